@@ -2,12 +2,18 @@ package main
 
 import (
 	"github.com/onodera-punpun/sponewm/commands"
+	"github.com/onodera-punpun/sponewm/logger"
 	"github.com/onodera-punpun/sponewm/wm"
+	"github.com/onodera-punpun/sponewm/xclient"
 )
 
 func newHacks() wm.CommandHacks {
 	return wm.CommandHacks{
 		MouseResizeDirection: mouseResizeDirection,
+		RuleMatch:            ruleMatch,
+		CheckCommand:         checkCommand,
+
+		CycleClientRunWithKeyStr: cycleClientRunWithKeyStr,
 	}
 }
 
@@ -18,3 +24,39 @@ func mouseResizeDirection(cmdStr string) (string, error) {
 	}
 	return cmd.(*commands.MouseResize).Direction, nil
 }
+
+// ruleMatch evaluates the match expression cmdStr for c. It is called from
+// within the main event loop.
+func ruleMatch(cmdStr string, c wm.Client) (bool, error) {
+	return commands.MatchClient(cmdStr, c.(*xclient.Client))
+}
+
+// checkCommand returns an error if cmdStr can't be parsed by Gribble, or if it
+// is a RunOrRaise command whose Match isn't a valid match expression.
+func checkCommand(cmdStr string) error {
+	cmd, err := commands.Env.Command(cmdStr)
+	if err != nil {
+		return err
+	}
+	if cmd, ok := cmd.(*commands.RunOrRaise); ok {
+		return commands.CheckMatch(cmd.Match)
+	}
+	return nil
+}
+
+// cycleClientRunWithKeyStr runs CycleClientNext or CycleClientPrev from a key
+// binding, so that the window switcher knows which modifiers to wait for.
+// It is called from within the main event loop.
+func cycleClientRunWithKeyStr(keyStr, cmdStr string) {
+	cmd, err := commands.Env.Command(cmdStr)
+	if err != nil {
+		logger.Warning.Println(err)
+		return
+	}
+	switch cmd := cmd.(type) {
+	case *commands.CycleClientNext:
+		cmd.RunWithKeyStr(keyStr)
+	case *commands.CycleClientPrev:
+		cmd.RunWithKeyStr(keyStr)
+	}
+}
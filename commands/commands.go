@@ -13,7 +13,9 @@ import (
 	"github.com/BurntSushi/xgbutil/xprop"
 	"github.com/BurntSushi/xgbutil/xrect"
 
+	"github.com/onodera-punpun/sponewm/config"
 	"github.com/onodera-punpun/sponewm/focus"
+	"github.com/onodera-punpun/sponewm/layout"
 	"github.com/onodera-punpun/sponewm/logger"
 	"github.com/onodera-punpun/sponewm/wm"
 	"github.com/onodera-punpun/sponewm/workspace"
@@ -24,10 +26,21 @@ import (
 // this list cannot be executed.
 var Env = gribble.New([]gribble.Command{
 	&Close{},
+	&Exec{},
+	&ExecOn{},
+	&RunOrRaise{},
 	&Focus{},
 	&FocusRaise{},
+	&FocusNext{},
+	&FocusPrev{},
+	&FocusUrgent{},
+	&CycleClientNext{},
+	&CycleClientPrev{},
+	&FocusDirection{},
+	&SwapDirection{},
 	&FrameDecor{},
 	&FrameNada{},
+	&KeyMode{},
 	&ToggleFloating{},
 	&ToggleMaximize{},
 	&ToggleStackAbove{},
@@ -42,17 +55,33 @@ var Env = gribble.New([]gribble.Command{
 	&MovePointerRelative{},
 	&Raise{},
 	&Resize{},
+	&ReloadConfig{},
 	&Restart{},
 	&Quit{},
 	&Unmaximize{},
 	&Workspace{},
 	&WorkspaceSendClient{},
 	&WorkspaceWithClient{},
+	&WorkspaceBack{},
+	&WorkspaceForward{},
+	&AddWorkspace{},
+	&RemoveWorkspace{},
+	&RenameWorkspace{},
+	&MoveWorkspace{},
 
 	&Tile{},
 	&Untile{},
 	&TileToggle{},
+	&SetLayout{},
+	&CycleLayout{},
 	&MakeMaster{},
+	&MasterAdd{},
+	&MasterRemove{},
+	&MasterGrow{},
+	&MasterShrink{},
+	&BspPreselect{},
+	&BspRotate{},
+	&BspFlip{},
 
 	&GetActive{},
 	&GetAllClients{},
@@ -70,7 +99,9 @@ var Env = gribble.New([]gribble.Command{
 	&GetHeadHeight{},
 	&GetHeadWidth{},
 	&GetHeadWorkspace{},
+	&GetKeyMode{},
 	&GetLayout{},
+	&GetLayoutList{},
 	&GetWorkspace{},
 	&GetWorkspaceId{},
 	&GetWorkspaceList{},
@@ -148,6 +179,116 @@ func (cmd Close) Run() gribble.Value {
 	})
 }
 
+type Exec struct {
+	Command string `param:"1"`
+	Help    string `
+Runs Command with the shell (sh -c). The program keeps running when SponeWM
+quits or restarts.
+`
+}
+
+func (cmd Exec) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if err := wm.Spawn(cmd.Command, ""); err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type ExecOn struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Command   string      `param:"2"`
+	Help      string      `
+Runs Command with the shell (sh -c), like Exec, and puts the windows it opens
+in the next 30 seconds on the workspace specified by Workspace, whether that
+workspace is visible or not.
+
+Windows are recognized by their process id (_NET_WM_PID) or by the startup
+notification id (_NET_STARTUP_ID) that is passed to the program in the
+DESKTOP_STARTUP_ID environment variable. Programs that set neither, or that
+hand their windows to an instance that was already running, are placed as
+usual.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd ExecOn) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		name := ""
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			name = wrk.Name
+		})
+		if len(name) == 0 {
+			return cmdError("Could not find workspace '%v'.", cmd.Workspace)
+		}
+		if err := wm.Spawn(cmd.Command, name); err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type RunOrRaise struct {
+	Match   string `param:"1"`
+	Command string `param:"2"`
+	Help    string `
+Switches to the workspace of a window for which the command Match returns 1,
+and focuses and raises it. If no window matches, Command is run with the
+shell (sh -c) like Exec.
+
+Match may only use the Match* commands, True, False, Not, And and Or, nested
+in parentheses, with window ids, 0 or 1 and strings as arguments. In a string,
+a backslash escapes the character after it. In Match, ":client:" refers to the
+window being tested. For example: MatchClientClass ":client:" "Firefox"
+Bindings with an invalid Match are reported when the bindings are loaded.
+
+When several windows match, the most recently focused one is raised, unless
+the active window matches, in which case the least recently focused one is.
+So invoking RunOrRaise repeatedly cycles through all matching windows.
+`
+}
+
+func (cmd RunOrRaise) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		// Candidates are in the order they were last focused.
+		matched := make([]*xclient.Client, 0)
+		for i := len(focus.Clients) - 1; i >= 0; i-- {
+			c, ok := focus.Clients[i].(*xclient.Client)
+			if !ok {
+				continue
+			}
+			ok, err := MatchClient(cmd.Match, c)
+			if err != nil {
+				return cmdError(err.Error())
+			}
+			if ok {
+				matched = append(matched, c)
+			}
+		}
+		if len(matched) == 0 {
+			if err := wm.Spawn(cmd.Command, ""); err != nil {
+				return cmdError(err.Error())
+			}
+			return nil
+		}
+
+		c := matched[0]
+		if c.IsActive() {
+			c = matched[len(matched)-1]
+		}
+		if wrk, ok := c.Workspace().(*workspace.Workspace); ok {
+			wm.SetWorkspace(wrk, false)
+		}
+		c.Deiconify()
+		c.Focus()
+		c.Raise()
+		return int(c.Id())
+	})
+}
+
 type Focus struct {
 	Client gribble.Any `param:"1" types:"int,string"`
 	Help   string      `
@@ -204,6 +345,81 @@ func (cmd FocusRaise) Run() gribble.Value {
 	})
 }
 
+type FocusNext struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Focuses and raises the window that comes after the active window in the
+current layout of the workspace specified by Workspace. When the end of the
+layout is reached, the first window is focused.
+
+In the Monocle layout, this flips through the windows in order.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd FocusNext) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			if cycler, ok := wrk.LayoutCurrent().(layout.Cycler); ok {
+				cycler.FocusNext()
+			}
+		})
+		return nil
+	})
+}
+
+type FocusPrev struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Focuses and raises the window that comes before the active window in the
+current layout of the workspace specified by Workspace. When the start of the
+layout is reached, the last window is focused.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd FocusPrev) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			if cycler, ok := wrk.LayoutCurrent().(layout.Cycler); ok {
+				cycler.FocusPrev()
+			}
+		})
+		return nil
+	})
+}
+
+type FocusUrgent struct {
+	Help string `
+Switches to the workspace of the window that has been demanding attention for
+the longest time, and focuses and raises it. Focusing a window stops it from
+demanding attention, so invoking FocusUrgent repeatedly visits every urgent
+window in turn.
+
+The id of the window is returned, or nothing if no window demands attention.
+`
+}
+
+func (cmd FocusUrgent) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		c := xclient.OldestUrgent()
+		if c == nil {
+			return nil
+		}
+		if wrk, ok := c.Workspace().(*workspace.Workspace); ok {
+			wm.SetWorkspace(wrk, false)
+		}
+		c.Deiconify()
+		c.Focus()
+		c.Raise()
+		return int(c.Id())
+	})
+}
+
 type FrameDecor struct {
 	Client gribble.Any `param:"1" types:"int,string"`
 	Help   string      `
@@ -240,6 +456,32 @@ func (cmd FrameNada) Run() gribble.Value {
 	})
 }
 
+type KeyMode struct {
+	Name string `param:"1"`
+	Help string `
+Enters the key mode specified by Name. While a key mode is active, the
+keyboard is grabbed and key presses run the commands bound in that mode's key
+map (declared in the "modes" section of bindings.json) instead of the
+commands in the "keys" section.
+
+A key mode is left when Escape is pressed or when its timeout expires. One
+shot modes are also left after the first key press, which can be used to
+define key chords. For example, binding "Mod4-w" to 'KeyMode "window"' and
+"h" to a command in the "window" mode runs that command with "Mod4-w h".
+
+Use "default" as Name to leave the active key mode.
+`
+}
+
+func (cmd KeyMode) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if err := wm.KeyModeSet(cmd.Name); err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
 type ToggleFloating struct {
 	Client gribble.Any `param:"1" types:"int,string"`
 	Help   string      `
@@ -385,6 +627,9 @@ TopRight, BottomLeft and BottomRight. When "Infer" is used, the direction
 is determined based on where the pointer is on the window when the drag is
 initiated.
 
+In the Bsp tiling layout, resizing a window moves the split borders next to
+the sides of the window that are dragged.
+
 This is a special command that can only be assigned in SponeWM's mouse
 configuration file. Invoking this command in any other way has no effect.
 `
@@ -523,6 +768,30 @@ func (cmd MovePointerRelative) Run() gribble.Value {
 	})
 }
 
+type ReloadConfig struct {
+	Help string `
+Reads settings.json, bindings.json and the theme images again and applies them
+without restarting SponeWM. Gaps and padding are applied by placing every
+visible workspace again, mouse and key bindings are grabbed again, and window
+decorations are rebuilt in place.
+
+Changes to the list of workspaces and the default layout only take effect
+after a restart.
+
+If settings.json or bindings.json can't be read, nothing is changed and an
+error is returned.
+`
+}
+
+func (cmd ReloadConfig) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if err := wm.ReloadConfig(); err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
 type Restart struct {
 	Help string `
 Restarts SponeWM in place using exec. This should be used to reload SponeWM
@@ -605,17 +874,27 @@ func (cmd Unmaximize) Run() gribble.Value {
 type Workspace struct {
 	Workspace gribble.Any `param:"1" types:"int,string"`
 	Help      string      `
-Sets the current workspace to the one specified by Workspace.
+Sets the current workspace to the one specified by Workspace. If it is the
+current workspace already and the 'workspacebackandforth' setting is enabled,
+the workspace shown on the active head right before it is shown again. So
+running it twice toggles between two workspaces.
 
 Workspace may be a workspace index (integer) starting at 0, or a workspace
 name.
+
+When the 'dynamicworkspaces' setting is enabled, a workspace that doesn't
+exist is added.
 `
 }
 
 func (cmd Workspace) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
-		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
-			wm.SetWorkspace(wrk, false)
+		withWorkspaceOrNew(cmd.Workspace, func(wrk *workspace.Workspace) {
+			if wrk == wm.Workspace() && config.Settings.BackAndForth {
+				wm.WorkspaceBackAndForth()
+			} else {
+				wm.SetWorkspace(wrk, false)
+			}
 			wm.FocusFallback()
 		})
 		return nil
@@ -631,13 +910,16 @@ Sends the window specified by Client to the workspace specified by Workspace.
 Workspace may be a workspace index (integer) starting at 0, or a workspace
 name.
 
+When the 'dynamicworkspaces' setting is enabled, a workspace that doesn't
+exist is added.
+
 Client may be the window id or a substring that matches a window name.
 `
 }
 
 func (cmd WorkspaceSendClient) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
-		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+		withWorkspaceOrNew(cmd.Workspace, func(wrk *workspace.Workspace) {
 			withClient(cmd.Client, func(c *xclient.Client) {
 				wrk.Add(c)
 			})
@@ -656,13 +938,16 @@ the window specified by Client to that workspace.
 Workspace may be a workspace index (integer) starting at 0, or a workspace
 name.
 
+When the 'dynamicworkspaces' setting is enabled, a workspace that doesn't
+exist is added.
+
 Client may be the window id or a substring that matches a window name.
 `
 }
 
 func (cmd WorkspaceWithClient) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
-		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+		withWorkspaceOrNew(cmd.Workspace, func(wrk *workspace.Workspace) {
 			withClient(cmd.Client, func(c *xclient.Client) {
 				c.Raise()
 				wrk.Add(c)
@@ -674,6 +959,146 @@ func (cmd WorkspaceWithClient) Run() gribble.Value {
 	})
 }
 
+type WorkspaceBack struct {
+	Help string `
+Shows the workspace that was shown on the active head before the current
+workspace. Every head has its own history, so invoking WorkspaceBack
+repeatedly goes further back. If the workspace is visible on another head,
+the two heads swap workspaces.
+`
+}
+
+func (cmd WorkspaceBack) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		wm.WorkspaceBack()
+		wm.FocusFallback()
+		return nil
+	})
+}
+
+type WorkspaceForward struct {
+	Help string `
+Shows the workspace that was shown on the active head before the last
+invocation of WorkspaceBack, i.e., it goes forward in the history again.
+`
+}
+
+func (cmd WorkspaceForward) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		wm.WorkspaceForward()
+		wm.FocusFallback()
+		return nil
+	})
+}
+
+type AddWorkspace struct {
+	Name string `param:"1"`
+	Help string `
+Adds a new workspace called Name at the end of the list of workspaces. Name
+must not be used by another workspace, regardless of case.
+`
+}
+
+func (cmd AddWorkspace) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if err := wm.AddWorkspace(cmd.Name); err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type RemoveWorkspace struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Removes the workspace specified by Workspace. Only workspaces without windows
+can be removed, and there must be at least as many workspaces left as there
+are heads.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd RemoveWorkspace) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var err error
+		found := false
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			found = true
+			err = wm.RemoveWorkspace(wrk)
+		})
+		switch {
+		case !found:
+			return cmdError("No such workspace '%v'.", cmd.Workspace)
+		case err != nil:
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type RenameWorkspace struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Name      string      `param:"2"`
+	Help      string      `
+Renames the workspace specified by Workspace to Name. Name must not be used
+by another workspace, regardless of case.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd RenameWorkspace) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var err error
+		found := false
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			found = true
+			err = wm.RenameWorkspace(wrk, cmd.Name)
+		})
+		switch {
+		case !found:
+			return cmdError("No such workspace '%v'.", cmd.Workspace)
+		case err != nil:
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type MoveWorkspace struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Index     int         `param:"2"`
+	Help      string      `
+Moves the workspace specified by Workspace to position Index in the list of
+workspaces, starting at 0. The workspaces in between shift by one. Pagers are
+told about the new order.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd MoveWorkspace) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var err error
+		found := false
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			found = true
+			err = wm.MoveWorkspace(wrk, cmd.Index)
+		})
+		switch {
+		case !found:
+			return cmdError("No such workspace '%v'.", cmd.Workspace)
+		case err != nil:
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
 type TagGet struct {
 	Client gribble.Any `param:"1" types:"int,string"`
 	Name   string      `param:"2"`
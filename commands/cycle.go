@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"github.com/BurntSushi/gribble"
+
+	"github.com/onodera-punpun/sponewm/focus"
+	"github.com/onodera-punpun/sponewm/switcher"
+	"github.com/onodera-punpun/sponewm/wm"
+	"github.com/onodera-punpun/sponewm/workspace"
+	"github.com/onodera-punpun/sponewm/xclient"
+)
+
+type CycleClientNext struct {
+	OnlyActiveWorkspace string `param:"1"`
+	ShowIconified       string `param:"2"`
+	Help                string `
+Cycles back through the windows in the order they were last focused, i.e.,
+the first step selects the window that was focused before the active one.
+
+When bound to a key with modifiers, e.g., "Mod1-Tab", a switcher listing the
+windows is shown. Pressing the key again selects the next window, and the
+selected window is focused and raised once the modifiers are released. Escape
+closes the switcher without changing focus. Otherwise, the next window is
+focused right away.
+
+When OnlyActiveWorkspace is "yes", only the windows on the active workspace
+are listed. Otherwise the windows on every workspace are, and choosing one
+switches to its workspace. When ShowIconified is "yes", iconified windows are
+listed too, and they are deiconified when chosen.
+`
+}
+
+func (cmd CycleClientNext) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		cmd.RunWithKeyStr("")
+		return nil
+	})
+}
+
+// RunWithKeyStr cycles forward. keyStr is the key that the command is bound
+// to, whose modifiers keep the switcher open. It must be called from within
+// the main event loop.
+func (cmd CycleClientNext) RunWithKeyStr(keyStr string) {
+	cycleClient(keyStr, true,
+		stringBool(cmd.OnlyActiveWorkspace), stringBool(cmd.ShowIconified))
+}
+
+type CycleClientPrev struct {
+	OnlyActiveWorkspace string `param:"1"`
+	ShowIconified       string `param:"2"`
+	Help                string `
+Does the same as CycleClientNext, but in the opposite direction. So the first
+step selects the window that was focused least recently.
+`
+}
+
+func (cmd CycleClientPrev) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		cmd.RunWithKeyStr("")
+		return nil
+	})
+}
+
+// RunWithKeyStr cycles backward. See CycleClientNext.RunWithKeyStr.
+func (cmd CycleClientPrev) RunWithKeyStr(keyStr string) {
+	cycleClient(keyStr, false,
+		stringBool(cmd.OnlyActiveWorkspace), stringBool(cmd.ShowIconified))
+}
+
+// cycleClient moves the selection of the window switcher if it is shown, and
+// shows it otherwise.
+func cycleClient(keyStr string, forward, onlyActive, showIconified bool) {
+	if wm.SwitcherShowing() {
+		if forward {
+			wm.SwitcherNext()
+		} else {
+			wm.SwitcherPrev()
+		}
+		return
+	}
+
+	items := cycleItems(onlyActive, showIconified)
+	if len(items) == 0 {
+		return
+	}
+
+	// The first item is the active client, if there is one, in which case
+	// the first step forward skips it.
+	selected := len(items) - 1
+	if forward {
+		selected = 0
+		if items[0].(*xclient.Client).IsActive() {
+			selected = 1 % len(items)
+		}
+	}
+	if !wm.SwitcherStart(keyStr, items, selected, cycleChoose) {
+		cycleChoose(items[selected])
+	}
+}
+
+// cycleItems returns the clients that can be cycled through, most recently
+// focused first.
+func cycleItems(onlyActive, showIconified bool) []switcher.Item {
+	items := make([]switcher.Item, 0)
+	for i := len(focus.Clients) - 1; i >= 0; i-- {
+		c, ok := focus.Clients[i].(*xclient.Client)
+		if !ok || c.IsSkipTaskbar() {
+			continue
+		}
+		if c.Iconified() && !showIconified {
+			continue
+		}
+		if onlyActive && !c.IsSticky() && c.Workspace() != wm.Workspace() {
+			continue
+		}
+		items = append(items, c)
+	}
+	return items
+}
+
+// cycleChoose switches to the workspace of the client chosen in the window
+// switcher, and focuses and raises it.
+func cycleChoose(item switcher.Item) {
+	// The client may have been unmanaged while the switcher was shown.
+	c, ok := wm.FindManagedClient(item.Id()).(*xclient.Client)
+	if !ok {
+		return
+	}
+	if wrk, ok := c.Workspace().(*workspace.Workspace); ok {
+		wm.SetWorkspace(wrk, false)
+	}
+	c.Deiconify()
+	c.Focus()
+	c.Raise()
+}
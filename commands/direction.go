@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/BurntSushi/gribble"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/layout"
+	"github.com/onodera-punpun/sponewm/wm"
+	"github.com/onodera-punpun/sponewm/workspace"
+	"github.com/onodera-punpun/sponewm/xclient"
+)
+
+type FocusDirection struct {
+	Direction string `param:"1"`
+	Help      string `
+Focuses and raises the nearest visible window in Direction from the active
+window, based on the positions of their frames. Windows on other heads are
+considered too.
+
+Valid values for Direction are: Left, Right, Up and Down.
+`
+}
+
+func (cmd FocusDirection) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if !validDirection(cmd.Direction) {
+			return cmdError("Unknown direction '%s'.", cmd.Direction)
+		}
+		return withFocused(func(c *xclient.Client) {
+			if c2 := clientInDirection(c, cmd.Direction); c2 != nil {
+				c2.Focus()
+				c2.Raise()
+			}
+		})
+	})
+}
+
+type SwapDirection struct {
+	Direction string `param:"1"`
+	Help      string `
+Swaps the active window with the nearest visible window in Direction, which
+is found like in FocusDirection. The active window keeps focus.
+
+In tiling layouts, the two windows trade places in the layout. Floating
+windows trade geometries. When the windows are on different heads, each is
+moved to the workspace of the other, where it takes the place of the other
+window. An error is returned when the windows can't be swapped, e.g., when
+one of them is floating and the other one is tiled.
+
+Valid values for Direction are: Left, Right, Up and Down.
+`
+}
+
+func (cmd SwapDirection) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		if !validDirection(cmd.Direction) {
+			return cmdError("Unknown direction '%s'.", cmd.Direction)
+		}
+		var err string
+		val := withFocused(func(c *xclient.Client) {
+			c2 := clientInDirection(c, cmd.Direction)
+			if c2 == nil {
+				return
+			}
+
+			wrk1, ok1 := c.Workspace().(*workspace.Workspace)
+			wrk2, ok2 := c2.Workspace().(*workspace.Workspace)
+			if !ok1 || !ok2 {
+				err = cmdError("Sticky windows can't be swapped.")
+				return
+			}
+			notSwappable := func() {
+				err = cmdError("'%s' and '%s' are not in the same layout, "+
+					"or their layout can't swap windows.", c, c2)
+			}
+			if wrk1 != wrk2 {
+				_, tiled1 := c.Layout().(layout.Tiler)
+				_, tiled2 := c2.Layout().(layout.Tiler)
+				if tiled1 != tiled2 {
+					notSwappable()
+					return
+				}
+				wrk1.Exchange(c, wrk2, c2)
+				c.Focus()
+				return
+			}
+
+			lay := c.Layout()
+			swapper, ok := lay.(layout.Swapper)
+			if lay != c2.Layout() || !ok {
+				notSwappable()
+				return
+			}
+			swapper.Swap(c, c2)
+		})
+		if len(err) > 0 {
+			return err
+		}
+		return val
+	})
+}
+
+// validDirection returns true if dir is one of the directions accepted by
+// FocusDirection and SwapDirection.
+func validDirection(dir string) bool {
+	switch strings.ToLower(dir) {
+	case "left", "right", "up", "down":
+		return true
+	}
+	return false
+}
+
+// clientInDirection returns the visible client closest to c in the direction
+// dir, or nil if there is none. Only clients whose centers lie beyond the
+// center of c in that direction are considered. Among those, clients that
+// overlap c across the direction (e.g., vertically when dir is left) come
+// first, and the client with the smallest distance between centers wins.
+func clientInDirection(c *xclient.Client, dir string) *xclient.Client {
+	dir = strings.ToLower(dir)
+	geom := c.Geom()
+	cx, cy := rectCenter(geom)
+
+	var best *xclient.Client
+	bestOverlap, bestDist := false, 0
+	for _, c2_ := range wm.Clients {
+		c2 := c2_.(*xclient.Client)
+		if c2 == c || !c2.IsMapped() || c2.Iconified() ||
+			c2.PrimaryType() != xclient.TypeNormal {
+
+			continue
+		}
+		geom2 := c2.Geom()
+		cx2, cy2 := rectCenter(geom2)
+
+		var along, across int
+		var overlap bool
+		switch dir {
+		case "left", "right":
+			along, across = cx-cx2, cy-cy2
+			if dir == "right" {
+				along = -along
+			}
+			overlap = geom.Y() < geom2.Y()+geom2.Height() &&
+				geom2.Y() < geom.Y()+geom.Height()
+		case "up", "down":
+			along, across = cy-cy2, cx-cx2
+			if dir == "down" {
+				along = -along
+			}
+			overlap = geom.X() < geom2.X()+geom2.Width() &&
+				geom2.X() < geom.X()+geom.Width()
+		}
+		if along <= 0 {
+			continue
+		}
+		if across < 0 {
+			across = -across
+		}
+
+		dist := along + across
+		if best == nil || (overlap && !bestOverlap) ||
+			(overlap == bestOverlap && dist < bestDist) {
+
+			best, bestOverlap, bestDist = c2, overlap, dist
+		}
+	}
+	return best
+}
+
+// rectCenter returns the center point of a rectangle.
+func rectCenter(r xrect.Rect) (int, int) {
+	return r.X() + r.Width()/2, r.Y() + r.Height()/2
+}
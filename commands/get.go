@@ -8,6 +8,7 @@ import (
 
 	"github.com/BurntSushi/gribble"
 
+	"github.com/onodera-punpun/sponewm/layout"
 	"github.com/onodera-punpun/sponewm/logger"
 	"github.com/onodera-punpun/sponewm/wm"
 	"github.com/onodera-punpun/sponewm/workspace"
@@ -340,12 +341,30 @@ func (cmd GetHeadWorkspace) Run() gribble.Value {
 	})
 }
 
+type GetKeyMode struct {
+	Help string `
+Returns the name of the active key mode. When no key mode has been entered,
+"default" is returned.
+`
+}
+
+func (cmd GetKeyMode) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		return wm.KeyMode()
+	})
+}
+
 type GetLayout struct {
 	Workspace gribble.Any `param:"1" types:"int,string"`
 	Help      string      `
 Returns the name of the currently active (or "default") layout on the workspace
 specified by Workspace. Note that when a workspace is set to a tiling layout,
-it is still possible for clients to be floating.
+it is still possible for clients to be floating. For a workspace that isn't
+visible, this is the layout that it will use once it is shown.
+
+For the master/stack layouts, the name is followed by the number of master
+clients and the fraction of the workspace covered by the master area, all
+separated by spaces. For example: "MasterLeft 1 0.55".
 
 Workspace may be a workspace index (integer) starting at 0, or a workspace
 name.
@@ -354,14 +373,38 @@ name.
 
 func (cmd GetLayout) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
-		var w workspace.Workspacer = nil
+		name := ""
 		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
-			w = wrk
+			lay := wrk.LayoutChosen()
+			name = lay.Name()
+			if _, ok := lay.(*layout.MasterStack); ok {
+				ma := wrk.MasterArea()
+				name = fmt.Sprintf("%s %d %.2f", name, ma.Count, ma.Ratio)
+			}
 		})
-		if w == nil {
-			return ""
-		}
-		return w.LayoutName()
+		return name
+	})
+}
+
+type GetLayoutList struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Returns the names of all layouts of the workspace specified by Workspace,
+separated by new lines. Floating layouts come first, followed by tiling
+layouts. This is the order that CycleLayout goes through them.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd GetLayoutList) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		names := make([]string, 0)
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			names = wrk.LayoutNames()
+		})
+		return strings.Join(names, "\n")
 	})
 }
 
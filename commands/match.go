@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/BurntSushi/gribble"
@@ -22,7 +23,7 @@ func (cmd MatchClientMapped) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			matched = c.IsMapped()
+			matched = clientMatches("MatchClientMapped", c, "")
 		})
 		return boolToInt(matched)
 	})
@@ -44,11 +45,7 @@ func (cmd MatchClientClass) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			needle := strings.ToLower(cmd.Class)
-			haystack := strings.ToLower(c.Class().Class)
-			if strings.Contains(haystack, needle) {
-				matched = true
-			}
+			matched = clientMatches("MatchClientClass", c, cmd.Class)
 		})
 		return boolToInt(matched)
 	})
@@ -70,11 +67,7 @@ func (cmd MatchClientInstance) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			needle := strings.ToLower(cmd.Instance)
-			haystack := strings.ToLower(c.Class().Instance)
-			if strings.Contains(haystack, needle) {
-				matched = true
-			}
+			matched = clientMatches("MatchClientInstance", c, cmd.Instance)
 		})
 		return boolToInt(matched)
 	})
@@ -95,7 +88,7 @@ func (cmd MatchClientIsTransient) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			matched = c.IsTransient()
+			matched = clientMatches("MatchClientIsTransient", c, "")
 		})
 		return boolToInt(matched)
 	})
@@ -117,11 +110,7 @@ func (cmd MatchClientName) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			needle := strings.ToLower(cmd.Name)
-			haystack := strings.ToLower(c.Name())
-			if strings.Contains(haystack, needle) {
-				matched = true
-			}
+			matched = clientMatches("MatchClientName", c, cmd.Name)
 		})
 		return boolToInt(matched)
 	})
@@ -144,14 +133,37 @@ func (cmd MatchClientType) Run() gribble.Value {
 	return syncRun(func() gribble.Value {
 		matched := false
 		withClient(cmd.Client, func(c *xclient.Client) {
-			if strings.ToLower(cmd.Type) == c.PrimaryTypeString() {
-				matched = true
-			}
+			matched = clientMatches("MatchClientType", c, cmd.Type)
 		})
 		return boolToInt(matched)
 	})
 }
 
+// clientMatches returns the result of the Match command name for c, where
+// arg is the argument that follows the client, if any. It is shared by the
+// Match commands and match expressions.
+func clientMatches(name string, c *xclient.Client, arg string) bool {
+	if c == nil {
+		return false
+	}
+	needle := strings.ToLower(arg)
+	switch name {
+	case "MatchClientMapped":
+		return c.IsMapped()
+	case "MatchClientIsTransient":
+		return c.IsTransient()
+	case "MatchClientClass":
+		return strings.Contains(strings.ToLower(c.Class().Class), needle)
+	case "MatchClientInstance":
+		return strings.Contains(strings.ToLower(c.Class().Instance), needle)
+	case "MatchClientName":
+		return strings.Contains(strings.ToLower(c.Name()), needle)
+	case "MatchClientType":
+		return needle == c.PrimaryTypeString()
+	}
+	panic(fmt.Sprintf("BUG: Unknown match command: %s", name))
+}
+
 type True struct {
 	Help string `
 Always returns 1.
@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+
+	"github.com/onodera-punpun/sponewm/xclient"
+)
+
+// A match expression is a Gribble command built only from the Match*
+// commands, True, False, Not, And and Or, like the 'match' command of a
+// window rule or the Match of RunOrRaise. It isn't run by Env, whose
+// commands are executed one at a time by the main event loop. Instead, it is
+// parsed once and evaluated directly against a client, which ":client:"
+// refers to. So it can be evaluated from within the main event loop, e.g.,
+// while a client is being managed.
+//
+// Its grammar is a subset of Gribble's:
+//
+//	expr   = name { arg }
+//	arg    = "(" expr ")" | string | int
+//	string = '"' { char | "\" char } '"'
+//
+// where name is one of the commands in matchArgs, which also fixes the number
+// and kinds of their arguments. A backslash in a string escapes the character
+// after it. Other commands, even ones that return a client like GetActive,
+// aren't allowed. CheckMatch validates expressions when the configuration is
+// loaded, so that invalid ones are reported right away.
+
+// matchExpr is a parsed match command. Its arguments are strings, ints or
+// nested match commands.
+type matchExpr struct {
+	name string
+	args []interface{}
+}
+
+// matchArgs maps the commands allowed in a match expression to the kinds of
+// their arguments. 'c' is a client, 's' is a string and 'b' is a boolean,
+// i.e., 0, 1 or a nested match command.
+var matchArgs = map[string]string{
+	"True":                   "",
+	"False":                  "",
+	"Not":                    "b",
+	"And":                    "bb",
+	"Or":                     "bb",
+	"MatchClientMapped":      "c",
+	"MatchClientIsTransient": "c",
+	"MatchClientClass":       "cs",
+	"MatchClientInstance":    "cs",
+	"MatchClientName":        "cs",
+	"MatchClientType":        "cs",
+}
+
+// parsedMatch is a match expression as parsed by parseMatch.
+type parsedMatch struct {
+	expr *matchExpr
+	err  error
+}
+
+// matchCache holds the match expressions parsed so far, by their text. It is
+// only used from within the main event loop.
+var matchCache = make(map[string]parsedMatch)
+
+// matchCacheSize bounds the number of match expressions kept in matchCache.
+// The expressions of window rules and bindings are far fewer, but IPC
+// clients may send any number of different ones. When it is full, an
+// arbitrary expression is dropped, which is simply parsed again if needed.
+const matchCacheSize = 256
+
+// CheckMatch returns an error if cmdStr isn't a valid match expression.
+func CheckMatch(cmdStr string) error {
+	_, err := parseMatch(cmdStr)
+	return err
+}
+
+// MatchClient returns true if the match expression cmdStr holds for c, where
+// ":client:" refers to c. An error is returned if cmdStr isn't a valid match
+// expression. It must be called from within the main event loop.
+func MatchClient(cmdStr string, c *xclient.Client) (bool, error) {
+	parsed, ok := matchCache[cmdStr]
+	if !ok {
+		if len(matchCache) >= matchCacheSize {
+			for old := range matchCache {
+				delete(matchCache, old)
+				break
+			}
+		}
+		parsed.expr, parsed.err = parseMatch(cmdStr)
+		matchCache[cmdStr] = parsed
+	}
+	if parsed.err != nil {
+		return false, parsed.err
+	}
+	return parsed.expr.eval(c), nil
+}
+
+// eval returns the value of the match expression for c.
+func (e *matchExpr) eval(c *xclient.Client) bool {
+	switch e.name {
+	case "True":
+		return true
+	case "False":
+		return false
+	case "Not":
+		return !evalMatchBool(e.args[0], c)
+	case "And":
+		return evalMatchBool(e.args[0], c) && evalMatchBool(e.args[1], c)
+	case "Or":
+		return evalMatchBool(e.args[0], c) || evalMatchBool(e.args[1], c)
+	}
+
+	var target *xclient.Client
+	if e.args[0] == ":client:" {
+		target = c
+	} else {
+		withClient(e.args[0], func(found *xclient.Client) {
+			target = found
+		})
+	}
+	if target == nil {
+		return false
+	}
+	arg := ""
+	if len(e.args) > 1 {
+		arg = e.args[1].(string)
+	}
+	return clientMatches(e.name, target, arg)
+}
+
+// evalMatchBool returns the value of a boolean argument, which is either 0,
+// 1 or a nested match command.
+func evalMatchBool(arg interface{}, c *xclient.Client) bool {
+	if e, ok := arg.(*matchExpr); ok {
+		return e.eval(c)
+	}
+	return arg.(int) == 1
+}
+
+// parseMatch parses the match expression cmdStr.
+func parseMatch(cmdStr string) (*matchExpr, error) {
+	p := &matchParser{src: cmdStr}
+	expr, err := p.command()
+	if err == nil {
+		var tok string
+		if tok, err = p.next(); err == nil && len(tok) > 0 {
+			err = fmt.Errorf("unexpected '%s' after the command", tok)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid match command: %s",
+			cmdStr, err)
+	}
+	return expr, nil
+}
+
+// matchParser reads a match expression token by token.
+type matchParser struct {
+	src string
+	pos int
+}
+
+// next returns the next token, which is a parenthesis, a quoted string with
+// its quotes or a word. The empty string is returned at the end of src.
+func (p *matchParser) next() (string, error) {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return "", nil
+	}
+
+	start := p.pos
+	switch p.src[p.pos] {
+	case '(', ')':
+		p.pos++
+	case '"':
+		for p.pos++; p.pos < len(p.src) && p.src[p.pos] != '"'; p.pos++ {
+			if p.src[p.pos] == '\\' {
+				p.pos++
+			}
+		}
+		if p.pos >= len(p.src) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		p.pos++
+	default:
+		for p.pos < len(p.src) && !unicode.IsSpace(rune(p.src[p.pos])) &&
+			p.src[p.pos] != '(' && p.src[p.pos] != ')' {
+
+			p.pos++
+		}
+	}
+	return p.src[start:p.pos], nil
+}
+
+// command parses a command name followed by its arguments.
+func (p *matchParser) command() (*matchExpr, error) {
+	name, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	kinds, ok := matchArgs[name]
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a match command", name)
+	}
+
+	expr := &matchExpr{name: name, args: make([]interface{}, len(kinds))}
+	for i, kind := range kinds {
+		if expr.args[i], err = p.arg(name, kind); err != nil {
+			return nil, err
+		}
+	}
+	return expr, nil
+}
+
+// arg parses an argument of the command name of the given kind. See
+// matchArgs.
+func (p *matchParser) arg(name string, kind rune) (interface{}, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	var arg interface{}
+	switch {
+	case len(tok) == 0:
+		return nil, fmt.Errorf("missing arguments for '%s'", name)
+	case tok == ")":
+		return nil, fmt.Errorf("missing arguments for '%s'", name)
+	case tok == "(":
+		expr, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		if tok, err = p.next(); err != nil {
+			return nil, err
+		} else if tok != ")" {
+			return nil, fmt.Errorf("expected ')' but got '%s'", tok)
+		}
+		arg = expr
+	case tok[0] == '"':
+		arg = unquote(tok)
+	default:
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected '%s'", tok)
+		}
+		arg = n
+	}
+
+	switch arg := arg.(type) {
+	case *matchExpr:
+		if kind == 'b' {
+			return arg, nil
+		}
+	case int:
+		if kind == 'c' || (kind == 'b' && (arg == 0 || arg == 1)) {
+			return arg, nil
+		}
+	case string:
+		if kind == 'c' || kind == 's' {
+			return arg, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid argument for '%s'", name)
+}
+
+// unquote returns the contents of the quoted string tok, as returned by next,
+// where a backslash escapes the character after it.
+func unquote(tok string) string {
+	buf := make([]byte, 0, len(tok))
+	for i := 1; i < len(tok)-1; i++ {
+		if tok[i] == '\\' {
+			i++
+		}
+		buf = append(buf, tok[i])
+	}
+	return string(buf)
+}
@@ -10,56 +10,22 @@ import (
 
 	"github.com/BurntSushi/xgbutil/xrect"
 
-	"github.com/onodera-punpun/sponewm/logger"
+	"github.com/onodera-punpun/sponewm/heads"
 	"github.com/onodera-punpun/sponewm/wm"
 	"github.com/onodera-punpun/sponewm/workspace"
 	"github.com/onodera-punpun/sponewm/xclient"
 )
 
 // parsePos takes a string and parses an x or y position from it.
-// The magic here is that while a string could just be a simple integer,
-// it could also be a float greater than 0 but <= 1 in terms of the current
-// head's geometry.
+// See heads.ParsePos.
 func parsePos(geom xrect.Rect, gribblePos gribble.Any, y bool) (int, bool) {
-	switch pos := gribblePos.(type) {
-	case int:
-		return pos, true
-	case float64:
-		if pos <= 0 || pos > 1 {
-			logger.Warning.Printf("'%s' not in the valid range (0, 1].", pos)
-			return 0, false
-		}
-
-		if y {
-			return geom.Y() + int(float64(geom.Height())*pos), true
-		} else {
-			return geom.X() + int(float64(geom.Width())*pos), true
-		}
-	}
-	panic("unreachable")
+	return heads.ParsePos(geom, gribblePos, y)
 }
 
 // parseDim takes a string and parses a width or height dimension from it.
-// The magic here is that while a string could just be a simple integer,
-// it could also be a float greater than 0 but <= 1 in terms of the current
-// head's geometry.
+// See heads.ParseDim.
 func parseDim(geom xrect.Rect, gribbleDim gribble.Any, hght bool) (int, bool) {
-	switch dim := gribbleDim.(type) {
-	case int:
-		return dim, true
-	case float64:
-		if dim <= 0 || dim > 1 {
-			logger.Warning.Printf("'%s' not in the valid range (0, 1].", dim)
-			return 0, false
-		}
-
-		if hght {
-			return int(float64(geom.Height()) * dim), true
-		} else {
-			return int(float64(geom.Width()) * dim), true
-		}
-	}
-	panic("unreachable")
+	return heads.ParseDim(geom, gribbleDim, hght)
 }
 
 // stringBool takes a string and returns true if the string corresponds
@@ -158,6 +124,31 @@ func withWorkspace(wArg gribble.Any, f func(wrk *workspace.Workspace)) {
 	}
 }
 
+// withWorkspaceOrNew is like withWorkspace, but a workspace that doesn't
+// exist is added first if dynamic workspaces are enabled. A name that isn't
+// used adds a workspace with that name, and the index just past the last
+// workspace adds one with a unique name.
+func withWorkspaceOrNew(wArg gribble.Any,
+	f func(wrk *workspace.Workspace)) {
+
+	var wrk *workspace.Workspace
+	switch w := wArg.(type) {
+	case int:
+		wrk = wm.Heads.Workspaces.Get(w)
+		if wrk == nil && w == len(wm.Heads.Workspaces.Wrks) {
+			wrk = wm.AddDynamicWorkspace("")
+		}
+	case string:
+		wrk = wm.Heads.Workspaces.Find(w)
+		if wrk == nil {
+			wrk = wm.AddDynamicWorkspace(w)
+		}
+	}
+	if wrk != nil {
+		f(wrk)
+	}
+}
+
 func cmdError(format string, v ...interface{}) string {
 	return fmt.Sprintf("ERROR: %s", fmt.Sprintf(format, v...))
 }
@@ -1,8 +1,11 @@
 package commands
 
 import (
+	"strings"
+
 	"github.com/BurntSushi/gribble"
 
+	"github.com/onodera-punpun/sponewm/layout"
 	"github.com/onodera-punpun/sponewm/workspace"
 	"github.com/onodera-punpun/sponewm/xclient"
 )
@@ -72,6 +75,57 @@ func (cmd TileToggle) Run() gribble.Value {
 	})
 }
 
+type SetLayout struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Name      string      `param:"2"`
+	Help      string      `
+Makes the layout named Name the current layout of the workspace specified by
+Workspace. Choosing a tiling layout initiates tiling, while choosing a
+floating layout stops it. Use GetLayoutList to find the available layouts.
+
+If the workspace is not visible, the layout is used once it is shown.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd SetLayout) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var err error
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			err = wrk.SetLayout(cmd.Name)
+		})
+		if err != nil {
+			return cmdError(err.Error())
+		}
+		return nil
+	})
+}
+
+type CycleLayout struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Switches the workspace specified by Workspace to the layout that comes after
+its current layout in the list returned by GetLayoutList. After the last
+layout, the first one is used again.
+
+If the workspace is not visible, the layout is used once it is shown.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd CycleLayout) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			wrk.CycleLayout()
+		})
+		return nil
+	})
+}
+
 type MakeMaster struct {
 	Workspace gribble.Any `param:"1" types:"int,string"`
 	Client    gribble.Any `param:"2" types:"int,string"`
@@ -99,3 +153,218 @@ func (cmd MakeMaster) Run() gribble.Value {
 		return nil
 	})
 }
+
+type MasterAdd struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Adds one to the number of clients in the master area of the master/stack
+layouts (MasterLeft, MasterRight, MasterTop and MasterBottom) of the
+workspace specified by Workspace.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd MasterAdd) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			wrk.MasterArea().CountAdd(1)
+			wrk.Place()
+		})
+		return nil
+	})
+}
+
+type MasterRemove struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Removes one from the number of clients in the master area of the master/stack
+layouts of the workspace specified by Workspace. When there are no master
+clients left, every client is placed in the stack.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd MasterRemove) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			wrk.MasterArea().CountAdd(-1)
+			wrk.Place()
+		})
+		return nil
+	})
+}
+
+type MasterGrow struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Makes the master area of the master/stack layouts of the workspace specified
+by Workspace bigger.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd MasterGrow) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			wrk.MasterArea().Grow()
+			wrk.Place()
+		})
+		return nil
+	})
+}
+
+type MasterShrink struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Help      string      `
+Makes the master area of the master/stack layouts of the workspace specified
+by Workspace smaller.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd MasterShrink) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withWorkspace(cmd.Workspace, func(wrk *workspace.Workspace) {
+			wrk.MasterArea().Shrink()
+			wrk.Place()
+		})
+		return nil
+	})
+}
+
+type BspPreselect struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Direction string      `param:"2"`
+	Ratio     gribble.Any `param:"3" types:"float"`
+	Help      string      `
+Sets where the next window goes in the Bsp layout of the workspace specified
+by Workspace. The focused window is split in Direction, and the new window
+gets the fraction of its area specified by Ratio.
+
+Valid values for Direction are: Left, Right, Up, Down and None. None cancels
+the preselection, after which the focused window is split along its longer
+side. Ratio is clamped to the range 0.1 to 0.9.
+
+This command has no effect unless the current tiling layout of the workspace
+is Bsp.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+`
+}
+
+func (cmd BspPreselect) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var dir int
+		switch strings.ToLower(cmd.Direction) {
+		case "left":
+			dir = layout.BspLeft
+		case "right":
+			dir = layout.BspRight
+		case "up":
+			dir = layout.BspUp
+		case "down":
+			dir = layout.BspDown
+		case "none":
+			dir = layout.BspNone
+		default:
+			return cmdError("Unknown direction '%s'.", cmd.Direction)
+		}
+		ratio, ok := cmd.Ratio.(float64)
+		if !ok {
+			return cmdError("Ratio must be a number between 0.1 and 0.9.")
+		}
+		withBsp(cmd.Workspace, func(bsp *layout.Bsp) {
+			bsp.Preselect(dir, ratio)
+		})
+		return nil
+	})
+}
+
+type BspRotate struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Client    gribble.Any `param:"2" types:"int,string"`
+	Help      string      `
+Rotates the part of the Bsp layout made up of the window specified by Client
+and its sibling by 90 degrees clockwise, in the workspace specified by
+Workspace.
+
+This command has no effect unless the current tiling layout of the workspace
+is Bsp.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+
+Client may be the window id or a substring that matches a window name.
+`
+}
+
+func (cmd BspRotate) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		withBsp(cmd.Workspace, func(bsp *layout.Bsp) {
+			withClient(cmd.Client, func(c *xclient.Client) {
+				bsp.Rotate(c)
+			})
+		})
+		return nil
+	})
+}
+
+type BspFlip struct {
+	Workspace gribble.Any `param:"1" types:"int,string"`
+	Client    gribble.Any `param:"2" types:"int,string"`
+	Axis      string      `param:"3"`
+	Help      string      `
+Mirrors the part of the Bsp layout made up of the window specified by Client
+and its sibling, in the workspace specified by Workspace.
+
+When Axis is Horizontal, windows on the left and on the right are swapped.
+When Axis is Vertical, windows on top and at the bottom are swapped.
+
+This command has no effect unless the current tiling layout of the workspace
+is Bsp.
+
+Workspace may be a workspace index (integer) starting at 0, or a workspace
+name.
+
+Client may be the window id or a substring that matches a window name.
+`
+}
+
+func (cmd BspFlip) Run() gribble.Value {
+	return syncRun(func() gribble.Value {
+		var horizontal bool
+		switch strings.ToLower(cmd.Axis) {
+		case "horizontal":
+			horizontal = true
+		case "vertical":
+			horizontal = false
+		default:
+			return cmdError("Unknown axis '%s'.", cmd.Axis)
+		}
+		withBsp(cmd.Workspace, func(bsp *layout.Bsp) {
+			withClient(cmd.Client, func(c *xclient.Client) {
+				bsp.Flip(c, horizontal)
+			})
+		})
+		return nil
+	})
+}
+
+// withBsp calls f with the Bsp layout of the workspace specified by wArg, if
+// that is the workspace's current tiling layout.
+func withBsp(wArg gribble.Any, f func(bsp *layout.Bsp)) {
+	withWorkspace(wArg, func(wrk *workspace.Workspace) {
+		if bsp, ok := wrk.LayoutTiler().(*layout.Bsp); ok {
+			f(bsp)
+		}
+	})
+}
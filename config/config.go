@@ -1,7 +1,9 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -10,60 +12,110 @@ import (
 	"github.com/onodera-punpun/sponewm/logger"
 )
 
-var val map[string]interface{}
-var key map[string]interface{}
-var SettingsVal map[string]interface{}
-var SettingsKey map[string]interface{}
 var BindingsVal map[string]interface{}
 var BindingsKey map[string]interface{}
 
+// configDirOverride, when set, is returned by ConfigDir.
+var configDirOverride string
+
+// Initialize reads settings.json and bindings.json from the configuration
+// directory. Missing files, files that can't be parsed and invalid settings
+// are logged and replaced by their defaults. The files are never written to;
+// use DumpDefaults to create them.
 func Initialize() {
-	configs := []string{"settings", "bindings"}
-
-	for _, basename := range configs {
-		defaults := defaultConfig(basename)
-		var parsed map[string]interface{}
-
-		filename := ConfigDir() + "/" + basename + ".json"
-		if _, e := os.Stat(filename); e == nil {
-			input, err := ioutil.ReadFile(filename)
-			if err != nil {
-				logger.Error.Fatalf("Error reading"+basename+".json: %s", err)
-			}
-
-			err = json.Unmarshal(input, &parsed)
-			if err != nil {
-				logger.Error.Fatalf("Error reading"+basename+".json: %s", err)
-			}
-		}
-
-		val = make(map[string]interface{})
-		key = make(map[string]interface{})
-		for k, v := range defaults {
-			val[k] = v
-			key[k] = k
-		}
-		for k, v := range parsed {
-			val[k] = v
-			key[k] = k
-		}
-
-		err := writeConfig(filename)
-		if err != nil {
-			logger.Error.Fatalf("Error reading"+basename+".json: %s", err)
-		}
-
-		if basename == "settings" {
-			SettingsKey = key
-			SettingsVal = val
-		} else {
-			BindingsKey = key
-			BindingsVal = val
-		}
+	settings, err := readConfig(ConfigDir() + "/settings.json")
+	if err != nil {
+		logger.Warning.Printf("%s. Using the default settings instead.", err)
+	}
+	bindings, err := readConfig(ConfigDir() + "/bindings.json")
+	if err != nil {
+		logger.Warning.Printf("%s. Using the default bindings instead.", err)
 	}
+	apply(settings, bindings)
 }
 
+// Reload reads settings.json and bindings.json again. Unlike Initialize, the
+// current configuration is kept when either file can't be read or parsed.
+// Invalid settings are still logged and replaced by their defaults.
+func Reload() error {
+	settings, err := readConfig(ConfigDir() + "/settings.json")
+	if err != nil {
+		return err
+	}
+	bindings, err := readConfig(ConfigDir() + "/bindings.json")
+	if err != nil {
+		return err
+	}
+	apply(settings, bindings)
+	return nil
+}
+
+// apply makes the decoded contents of settings.json and bindings.json the
+// current configuration.
+func apply(settings, bindings map[string]interface{}) {
+	Settings = parseSettings(settings)
+
+	BindingsVal = merge(defaultBindings(), bindings)
+	BindingsKey = make(map[string]interface{})
+	for k := range BindingsVal {
+		BindingsKey[k] = k
+	}
+}
+
+// IsComment returns true if key is a comment in a configuration file.
+// Since JSON has no comments, any key starting with "//" is ignored.
+func IsComment(key string) bool {
+	return strings.HasPrefix(key, "//")
+}
+
+// merge returns the top-level keys of defaults overridden by those in
+// parsed.
+func merge(defaults, parsed map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range parsed {
+		merged[k] = v
+	}
+	return merged
+}
+
+// readConfig decodes the JSON object in filename. A file that doesn't exist
+// is not an error and results in an empty map. Numbers are decoded as
+// json.Number, so that integers can be told apart from fractions.
+func readConfig(filename string) (map[string]interface{}, error) {
+	var parsed map[string]interface{}
+
+	if _, e := os.Stat(filename); e != nil {
+		return nil, nil
+	}
+	input, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", filename, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %s", filename, err)
+	}
+	return parsed, nil
+}
+
+// SetConfigDir makes ConfigDir return dir instead of looking at the
+// environment. An empty dir restores the default behavior.
+func SetConfigDir(dir string) {
+	configDirOverride = dir
+}
+
+// ConfigDir returns the directory containing settings.json, bindings.json and
+// the theme images. Unless it is overridden with SetConfigDir, this is
+// $XDG_CONFIG_HOME/sponewm or $HOME/.config/sponewm.
 func ConfigDir() string {
+	if len(configDirOverride) > 0 {
+		return configDirOverride
+	}
+
 	var configDir string
 
 	xdgHome := os.Getenv("XDG_CONFIG_HOME")
@@ -81,48 +133,72 @@ func ConfigDir() string {
 	return configDir
 }
 
-func writeConfig(filename string,) error {
-	var err error
-
-	if _, e := os.Stat(ConfigDir()); e == nil {
-		txt, _ := json.MarshalIndent(val, "", "    ")
-		err = ioutil.WriteFile(filename, txt, 0644)
-	}
-
-	return err
-}
-
-func defaultConfig(basename string) map[string]interface{} {
-	if basename == "settings" {
-		return map[string]interface{}{
-			"defaultlayout":     "Floating",
-			"focusfollowsmouse": true,
-			"raisefollowsmouse": false,
-			"floatpadding":      40,
-			"gap":               20,
-			"tilepadding":       80,
-			"workspaces": []interface{}{
-				"www",
-				"irc",
-				"src",
-			},
-		}
-	} else {
-		return map[string]interface{}{
-			"root": map[string]interface{}{
-				"1":      "Focus \":mouse:\"",
-				"4":      "Workspace (GetWorkspacePrev)",
-				"5":      "Workspace (GetWorkspaceNext)",
-				"Mod4-4": "Workspace (GetWorkspacePrev)",
-				"Mod4-5": "Workspace (GetWorkspacePrev)",
+func defaultBindings() map[string]interface{} {
+	return map[string]interface{}{
+		"root": map[string]interface{}{
+			"1":      "Focus \":mouse:\"",
+			"4":      "Workspace (GetWorkspacePrev)",
+			"5":      "Workspace (GetWorkspaceNext)",
+			"Mod4-4": "Workspace (GetWorkspacePrev)",
+			"Mod4-5": "Workspace (GetWorkspacePrev)",
+		},
+		"client": map[string]interface{}{
+			"1":      "Focus \":mouse:\"",
+			"4":      "Workspace (GetWorkspacePrev)",
+			"5":      "Workspace (GetWorkspaceNext)",
+			"Mod4-4": "Workspace (GetWorkspacePrev)",
+			"Mod4-5": "Workspace (GetWorkspacePrev)",
+		},
+		"keys": map[string]interface{}{
+			"Mod4-1":       "Workspace \"www\"",
+			"Mod4-2":       "Workspace \"irc\"",
+			"Mod4-3":       "Workspace \"src\"",
+			"Mod4-Shift-1": "WorkspaceSendClient \"www\" (GetActive)",
+			"Mod4-Shift-2": "WorkspaceSendClient \"irc\" (GetActive)",
+			"Mod4-Shift-3": "WorkspaceSendClient \"src\" (GetActive)",
+			"Mod4-t":       "TileToggle (GetWorkspace)",
+			"Mod4-space":   "CycleLayout (GetWorkspace)",
+			"Mod4-Shift-c": "Close (GetActive)",
+			"Mod4-comma":   "MasterAdd (GetWorkspace)",
+			"Mod4-period":  "MasterRemove (GetWorkspace)",
+			"Mod4-h":       "MasterShrink (GetWorkspace)",
+			"Mod4-l":       "MasterGrow (GetWorkspace)",
+			"Mod4-j":       "FocusNext (GetWorkspace)",
+			"Mod4-k":       "FocusPrev (GetWorkspace)",
+			"Mod4-u":       "FocusUrgent",
+			"Mod4-Tab":     "WorkspaceBack",
+			"Mod4-s":       "KeyMode \"workspace\"",
+			"Mod4-w":       "KeyMode \"window\"",
+
+			"Mod1-Tab":       "CycleClientNext \"no\" \"no\"",
+			"Mod1-Shift-Tab": "CycleClientPrev \"no\" \"no\"",
+
+			"Mod4-Left":        "FocusDirection \"left\"",
+			"Mod4-Right":       "FocusDirection \"right\"",
+			"Mod4-Up":          "FocusDirection \"up\"",
+			"Mod4-Down":        "FocusDirection \"down\"",
+			"Mod4-Shift-Left":  "SwapDirection \"left\"",
+			"Mod4-Shift-Right": "SwapDirection \"right\"",
+			"Mod4-Shift-Up":    "SwapDirection \"up\"",
+			"Mod4-Shift-Down":  "SwapDirection \"down\"",
+		},
+		"modes": map[string]interface{}{
+			"window": map[string]interface{}{
+				"oneshot": true,
+				"keys": map[string]interface{}{
+					"c": "Close (GetActive)",
+					"f": "ToggleFloating (GetActive)",
+					"m": "ToggleMaximize (GetActive)",
+					"s": "ToggleSticky (GetActive)",
+				},
 			},
-			"client": map[string]interface{}{
-				"1":      "Focus \":mouse:\"",
-				"4":      "Workspace (GetWorkspacePrev)",
-				"5":      "Workspace (GetWorkspaceNext)",
-				"Mod4-4": "Workspace (GetWorkspacePrev)",
-				"Mod4-5": "Workspace (GetWorkspacePrev)",
+			"workspace": map[string]interface{}{
+				"timeout": 3000,
+				"keys": map[string]interface{}{
+					"h": "Workspace (GetWorkspacePrev)",
+					"l": "Workspace (GetWorkspaceNext)",
+				},
 			},
-		}
+		},
 	}
 }
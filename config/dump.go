@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+)
+
+// bindingsDocs documents the sections of the default bindings.json.
+var bindingsDocs = map[string]string{
+	"root": "Mouse bindings on the root window. Append ' up' to a " +
+		"button to bind its release instead of its press.",
+	"client": "Mouse bindings on client windows.",
+	"keys": "Key bindings that are always active. Append ' up' to a key " +
+		"to bind its release instead of its press.",
+	"modes": "Key modes entered with the KeyMode command. A mode has its " +
+		"own 'keys', is left with Escape or after 'timeout' milliseconds " +
+		"and, when 'oneshot' is true, after the first key press.",
+}
+
+// annotated is a key of a configuration file with its value and an
+// optional comment.
+type annotated struct {
+	key   string
+	doc   string
+	value interface{}
+}
+
+// DumpDefaults writes the default settings.json and bindings.json, annotated
+// with comments, to dir. The directory is created if needed, but existing
+// configuration files are never overwritten.
+func DumpDefaults(dir string) error {
+	settings := make([]annotated, 0, len(options))
+	defaults := settingsMap(DefaultSettings())
+	for _, opt := range options {
+		settings = append(settings,
+			annotated{opt.key, opt.doc, defaults[opt.key]})
+	}
+
+	bindings := make([]annotated, 0)
+	for section, val := range defaultBindings() {
+		bindings = append(bindings,
+			annotated{section, bindingsDocs[section], val})
+	}
+	sort.Sort(annotatedByKey(bindings))
+
+	files := map[string][]annotated{
+		"settings.json": settings,
+		"bindings.json": bindings,
+	}
+	for basename := range files {
+		filename := path.Join(dir, basename)
+		if _, err := os.Stat(filename); err == nil {
+			return fmt.Errorf("'%s' already exists. Please move it out of "+
+				"the way first.", filename)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for basename, keys := range files {
+		txt, err := marshalAnnotated(keys)
+		if err != nil {
+			return err
+		}
+		filename := path.Join(dir, basename)
+		if err := ioutil.WriteFile(filename, txt, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalAnnotated encodes keys, in order, as an indented JSON object. Every
+// comment is written as a "// key" entry right before the key it documents.
+func marshalAnnotated(keys []annotated) ([]byte, error) {
+	buf := bytes.NewBufferString("{\n")
+	for i, a := range keys {
+		if len(a.doc) > 0 {
+			doc, err := json.Marshal(a.doc)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(buf, "    \"// %s\": %s,\n", a.key, doc)
+		}
+
+		val, err := json.MarshalIndent(a.value, "    ", "    ")
+		if err != nil {
+			return nil, err
+		}
+		key, err := json.Marshal(a.key)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(buf, "    %s: %s", key, val)
+		if i < len(keys)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+type annotatedByKey []annotated
+
+func (as annotatedByKey) Len() int           { return len(as) }
+func (as annotatedByKey) Less(i, j int) bool { return as[i].key < as[j].key }
+func (as annotatedByKey) Swap(i, j int)      { as[i], as[j] = as[j], as[i] }
@@ -0,0 +1,606 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// SettingsConfig holds every option that can be set in settings.json.
+// Options that are missing or invalid keep their default value.
+type SettingsConfig struct {
+	DefaultLayout     string   `json:"defaultlayout"`
+	FocusModel        string   `json:"focusmodel"`
+	FocusDelay        int      `json:"focusdelay"`
+	RaiseFollowsMouse bool     `json:"raisefollowsmouse"`
+	FocusStealing     string   `json:"focusstealing"`
+	FloatPadding      int      `json:"floatpadding"`
+	Gap               int      `json:"gap"`
+	TilePadding       int      `json:"tilepadding"`
+	Workspaces        []string `json:"workspaces"`
+	DynamicWorkspaces bool     `json:"dynamicworkspaces"`
+	BackAndForth      bool     `json:"workspacebackandforth"`
+	AutoReload        bool     `json:"autoreload"`
+	Font              string   `json:"font"`
+	FontSize          int      `json:"fontsize"`
+
+	Rules     []RuleConfig `json:"rules"`
+	Autostart []string     `json:"autostart"`
+	Swallow   []string     `json:"swallow"`
+	NoSwallow []string     `json:"noswallow"`
+
+	// WorkspaceOutputs maps workspace names to the names of the RandR
+	// outputs they are pinned to.
+	WorkspaceOutputs map[string]string `json:"workspaceoutputs"`
+}
+
+// RuleConfig is a window rule from the "rules" list in settings.json. Match
+// is a Gribble command built from the Match* commands, True, False, Not, And
+// and Or, which is evaluated for every new window with ":client:" referring
+// to the new window. When it returns 1, the other fields are applied to the
+// window before it is placed. Fields that are left out have no effect.
+type RuleConfig struct {
+	Match string `json:"match"`
+
+	// Workspace is the name of the workspace the window is put on.
+	Workspace string `json:"workspace,omitempty"`
+
+	Floating    bool `json:"floating,omitempty"`
+	Sticky      bool `json:"sticky,omitempty"`
+	SkipTaskbar bool `json:"skiptaskbar,omitempty"`
+
+	// Layer is either "above" or "below".
+	Layer string `json:"layer,omitempty"`
+
+	// Frame is either "decor" or "nada".
+	Frame string `json:"frame,omitempty"`
+
+	// Geometry is the x, y, width and height of the window. Each value is
+	// either an int, which is a number of pixels, or a float64 in the range
+	// (0, 1], which is a fraction of the workspace geometry, like the
+	// arguments of Move and Resize. A width or height of 0 keeps the size
+	// the window asked for.
+	Geometry []interface{} `json:"geometry,omitempty"`
+
+	// OnTitleChange makes the rule match again whenever the title or class
+	// of a window changes. The actions are then applied to the window where
+	// it is, except that frames and geometry are left alone while the window
+	// is tiled.
+	OnTitleChange bool `json:"ontitlechange,omitempty"`
+}
+
+// Settings is the configuration currently in use. It is replaced by
+// Initialize.
+var Settings = DefaultSettings()
+
+// DefaultSettings returns the configuration used when settings.json doesn't
+// exist.
+func DefaultSettings() *SettingsConfig {
+	return &SettingsConfig{
+		DefaultLayout:     "Floating",
+		FocusModel:        "sloppy",
+		FocusDelay:        0,
+		RaiseFollowsMouse: false,
+		FocusStealing:     "smart",
+		FloatPadding:      40,
+		Gap:               20,
+		TilePadding:       80,
+		Workspaces:        []string{"www", "irc", "src"},
+		DynamicWorkspaces: false,
+		BackAndForth:      false,
+		AutoReload:        false,
+		Font:              "/usr/share/fonts/TTF/DejaVuSans.ttf",
+		FontSize:          12,
+		Rules:             []RuleConfig{},
+		Autostart:         []string{},
+		Swallow:           []string{},
+		NoSwallow:         []string{},
+		WorkspaceOutputs:  map[string]string{},
+	}
+}
+
+// settingsMap converts settings to the untyped representation found in
+// settings.json.
+func settingsMap(s *SettingsConfig) map[string]interface{} {
+	var m map[string]interface{}
+
+	txt, _ := json.Marshal(s)
+	json.Unmarshal(txt, &m)
+	return m
+}
+
+// option describes how the value of a single key in settings.json is
+// validated and stored, and how it is documented in the default
+// settings.json. apply must leave the settings untouched when it returns an
+// error.
+type option struct {
+	key   string
+	doc   string
+	apply func(s *SettingsConfig, v interface{}) error
+}
+
+var options = []option{
+	choiceOption("defaultlayout", []string{"Floating", "Tiling",
+		"MasterLeft", "MasterRight", "MasterTop", "MasterBottom",
+		"Monocle", "Grid", "Bsp"},
+		"The layout new workspaces start with: Floating, Tiling, "+
+			"MasterLeft, MasterRight, MasterTop, MasterBottom, Monocle, "+
+			"Grid or Bsp.",
+		func(s *SettingsConfig) *string { return &s.DefaultLayout }),
+	choiceOption("focusmodel", []string{"click", "sloppy", "strict"},
+		"How the pointer focuses windows. With 'click', a window is "+
+			"focused by the 'client' mouse bindings, e.g., when it is "+
+			"clicked, and the click is passed on to the window. New "+
+			"windows are focused when they are mapped. With 'sloppy', "+
+			"moving the pointer into a window focuses it, and the focus "+
+			"stays when the pointer moves on to the desktop. With "+
+			"'strict', moving the pointer onto the desktop removes the "+
+			"focus too.",
+		func(s *SettingsConfig) *string { return &s.FocusModel }),
+	intOption("focusdelay", 0, 5000,
+		"The time in milliseconds that the pointer must rest in a window "+
+			"before it is focused or raised by the pointer, so that "+
+			"windows the pointer only crosses are left alone. 0 acts "+
+			"right away.",
+		func(s *SettingsConfig) *int { return &s.FocusDelay }),
+	boolOption("raisefollowsmouse",
+		"Whether moving the pointer into a window raises it. This uses "+
+			"'focusdelay' too.",
+		func(s *SettingsConfig) *bool { return &s.RaiseFollowsMouse }),
+	choiceOption("focusstealing", []string{"off", "smart", "strict"},
+		"Whether new windows and windows that ask to be activated may take "+
+			"the focus away from the active window. With 'off', they "+
+			"always may. With 'smart', they may unless they report a user "+
+			"time (_NET_WM_USER_TIME) that is older than the one of the "+
+			"active window. With 'strict', they may only if they report a "+
+			"newer user time, or if they are dialogs of the active window. "+
+			"Windows that may not take focus demand attention instead. "+
+			"Requests from pagers and taskbars are always granted.",
+		func(s *SettingsConfig) *string { return &s.FocusStealing }),
+	intOption("floatpadding", 0, 1000,
+		"The minimum distance in pixels between a newly placed floating "+
+			"window and the edges of the screen.",
+		func(s *SettingsConfig) *int { return &s.FloatPadding }),
+	intOption("gap", 0, 1000,
+		"The space in pixels between tiled windows.",
+		func(s *SettingsConfig) *int { return &s.Gap }),
+	intOption("tilepadding", 0, 1000,
+		"The space in pixels between tiled windows and the edges of the "+
+			"screen.",
+		func(s *SettingsConfig) *int { return &s.TilePadding }),
+	workspacesOption("workspaces",
+		"The names of the workspaces created at startup.",
+		func(s *SettingsConfig) *[]string { return &s.Workspaces }),
+	boolOption("dynamicworkspaces",
+		"Whether workspaces are added on demand. The commands Workspace, "+
+			"WorkspaceSendClient and WorkspaceWithClient then add a "+
+			"workspace with a name that isn't used yet, or with a unique "+
+			"name for the index just past the last workspace. Workspaces "+
+			"that aren't in 'workspaces' are removed again when they are "+
+			"left while empty.",
+		func(s *SettingsConfig) *bool { return &s.DynamicWorkspaces }),
+	boolOption("workspacebackandforth",
+		"Whether the Workspace command goes back to the workspace shown "+
+			"right before on the active head when it is asked for the "+
+			"active workspace, so that it toggles between two workspaces.",
+		func(s *SettingsConfig) *bool { return &s.BackAndForth }),
+	boolOption("autoreload",
+		"Whether to run ReloadConfig whenever a file in the configuration "+
+			"directory changes.",
+		func(s *SettingsConfig) *bool { return &s.AutoReload }),
+	stringOption("font",
+		"The path of the TrueType font used for window titles in the "+
+			"window switcher.",
+		func(s *SettingsConfig) *string { return &s.Font }),
+	intOption("fontsize", 6, 72,
+		"The size in points of the font used in the window switcher.",
+		func(s *SettingsConfig) *int { return &s.FontSize }),
+	rulesOption("rules",
+		"Rules applied to new windows, in order. Each rule is an object "+
+			"with a 'match' command, e.g., "+
+			"'MatchClientClass \":client:\" \"Gimp\"'. It may only use "+
+			"the Match* commands, True, False, Not, And and Or, nested in "+
+			"parentheses, with window ids, \":client:\" for the window, "+
+			"0 or 1 and strings in double quotes, in which a backslash "+
+			"escapes the next character. Other commands aren't allowed in "+
+			"it. A rule also has any of the "+
+			"actions 'workspace' (name), 'floating', 'sticky', "+
+			"'skiptaskbar' (booleans), 'layer' (above or below), "+
+			"'frame' (decor or nada) and 'geometry' ([x, y, width, "+
+			"height] in pixels, e.g., 100, or as fractions of the "+
+			"workspace, e.g., 0.5 or 1.0). Rules "+
+			"with 'ontitlechange' set to true are also matched again "+
+			"whenever the title or class of a window changes.",
+		func(s *SettingsConfig) *[]RuleConfig { return &s.Rules }),
+	stringsOption("autostart",
+		"Commands run once when SponeWM starts, in order, e.g., "+
+			"'Exec \"urxvt\"' or 'ExecOn \"www\" \"firefox\"'. They are "+
+			"not run again when SponeWM restarts itself.",
+		func(s *SettingsConfig) *[]string { return &s.Autostart }),
+	stringMapOption("workspaceoutputs",
+		"The outputs that workspaces are pinned to, e.g., "+
+			"{\"www\": \"DP-1\", \"src\": \"HDMI-1\"}. The names of the "+
+			"outputs are the ones shown by xrandr. A pinned workspace is "+
+			"only shown on its output, and moves there when the outputs "+
+			"change. While its output isn't connected, it may be shown on "+
+			"any head.",
+		func(s *SettingsConfig) *map[string]string {
+			return &s.WorkspaceOutputs
+		}),
+	stringsOption("swallow",
+		"The window classes of terminals that are swallowed by the windows "+
+			"of programs started from them, e.g., 'URxvt'. A swallowed "+
+			"terminal is hidden and its window takes its place, until the "+
+			"window is closed.",
+		func(s *SettingsConfig) *[]string { return &s.Swallow }),
+	stringsOption("noswallow",
+		"The window classes that never swallow a terminal, e.g., 'Gimp'.",
+		func(s *SettingsConfig) *[]string { return &s.NoSwallow }),
+}
+
+// parseSettings builds a configuration from the decoded contents of
+// settings.json. Unknown keys and invalid values are logged, and invalid
+// values are replaced by their defaults.
+func parseSettings(parsed map[string]interface{}) *SettingsConfig {
+	s := DefaultSettings()
+
+	known := make(map[string]bool, len(options))
+	for _, opt := range options {
+		known[opt.key] = true
+
+		v, ok := parsed[opt.key]
+		if !ok {
+			continue
+		}
+		if err := opt.apply(s, v); err != nil {
+			logger.Warning.Printf("Invalid value for setting '%s': %s. "+
+				"Using the default value instead.", opt.key, err)
+		}
+	}
+
+	migrateSettings(s, parsed, known)
+
+	unknown := make([]string, 0)
+	for k := range parsed {
+		if !known[k] && !IsComment(k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		logger.Warning.Printf("Unknown setting '%s' in settings.json will "+
+			"be ignored.", k)
+	}
+	return s
+}
+
+// migrateSettings applies the settings in parsed that were replaced by other
+// settings, unless their replacements are set too, and logs which settings
+// replace them. They are then marked as known.
+func migrateSettings(s *SettingsConfig, parsed map[string]interface{},
+	known map[string]bool) {
+
+	// 'focusfollowsmouse' was replaced by 'focusmodel'.
+	if v, ok := parsed["focusfollowsmouse"]; ok {
+		known["focusfollowsmouse"] = true
+		logger.Warning.Printf("The setting 'focusfollowsmouse' is " +
+			"deprecated. Use 'focusmodel' instead, which is 'sloppy' or " +
+			"'click' for true or false.")
+		if _, ok := parsed["focusmodel"]; !ok {
+			switch v {
+			case true:
+				s.FocusModel = "sloppy"
+			case false:
+				s.FocusModel = "click"
+			default:
+				logger.Warning.Printf("Invalid value for setting "+
+					"'focusfollowsmouse': expected a boolean but got %s.",
+					jsonType(v))
+			}
+		}
+	}
+}
+
+func stringOption(key, doc string,
+	field func(*SettingsConfig) *string) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a string but got %s", jsonType(v))
+		}
+		if len(strings.TrimSpace(str)) == 0 {
+			return fmt.Errorf("the value must not be empty")
+		}
+		*field(s) = str
+		return nil
+	}}
+}
+
+// choiceOption is like stringOption, but the value must be one of choices.
+// The value is matched case insensitively and stored as written in choices.
+func choiceOption(key string, choices []string, doc string,
+	field func(*SettingsConfig) *string) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a string but got %s", jsonType(v))
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(str, choice) {
+				*field(s) = choice
+				return nil
+			}
+		}
+		return fmt.Errorf("'%s' is not one of: %s",
+			str, strings.Join(choices, ", "))
+	}}
+}
+
+func boolOption(key, doc string,
+	field func(*SettingsConfig) *bool) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("expected true or false but got %s",
+				jsonType(v))
+		}
+		*field(s) = b
+		return nil
+	}}
+}
+
+func intOption(key string, min, max int, doc string,
+	field func(*SettingsConfig) *int) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		n, err := toInt(v)
+		if err != nil {
+			return err
+		}
+		if n < min || n > max {
+			return fmt.Errorf("%d is not in the range [%d, %d]", n, min, max)
+		}
+		*field(s) = n
+		return nil
+	}}
+}
+
+func stringsOption(key, doc string,
+	field func(*SettingsConfig) *[]string) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		strs, err := toStrings(v)
+		if err != nil {
+			return err
+		}
+		*field(s) = strs
+		return nil
+	}}
+}
+
+func stringMapOption(key, doc string,
+	field func(*SettingsConfig) *map[string]string) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object but got %s", jsonType(v))
+		}
+		m := make(map[string]string, len(obj))
+		for k, val := range obj {
+			if IsComment(k) {
+				continue
+			}
+			str, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("expected a string for '%s' but got %s",
+					k, jsonType(val))
+			}
+			m[k] = str
+		}
+		*field(s) = m
+		return nil
+	}}
+}
+
+func workspacesOption(key, doc string,
+	field func(*SettingsConfig) *[]string) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		names, err := toStrings(v)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("at least one workspace is required")
+		}
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			if len(name) == 0 {
+				return fmt.Errorf("workspace names must not be empty")
+			}
+			if seen[strings.ToLower(name)] {
+				return fmt.Errorf("the workspace name '%s' is used more "+
+					"than once", name)
+			}
+			seen[strings.ToLower(name)] = true
+		}
+		*field(s) = names
+		return nil
+	}}
+}
+
+func rulesOption(key, doc string,
+	field func(*SettingsConfig) *[]RuleConfig) option {
+
+	return option{key, doc, func(s *SettingsConfig, v interface{}) error {
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of rules but got %s",
+				jsonType(v))
+		}
+		rules := make([]RuleConfig, len(list))
+		for i, item := range list {
+			if err := parseRule(item, &rules[i]); err != nil {
+				return fmt.Errorf("rule %d: %s", i+1, err)
+			}
+		}
+		*field(s) = rules
+		return nil
+	}}
+}
+
+// CheckMatch returns an error if cmdStr isn't a valid match expression, like
+// the 'match' command of a rule. It is set by the main package, since the
+// match expressions are parsed by the commands package.
+var CheckMatch func(cmdStr string) error
+
+// parseRule decodes and validates a single window rule.
+func parseRule(v interface{}, rule *RuleConfig) error {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an object but got %s", jsonType(v))
+	}
+
+	// Decode the rule again from JSON, so that the types of all values and
+	// unknown keys are checked by encoding/json.
+	uncommented := make(map[string]interface{}, len(obj))
+	for k, val := range obj {
+		if !IsComment(k) {
+			uncommented[k] = val
+		}
+	}
+	txt, err := json.Marshal(uncommented)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(txt))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(rule); err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(rule.Match)) == 0 {
+		return fmt.Errorf("the 'match' command must not be empty")
+	}
+	if CheckMatch != nil {
+		if err := CheckMatch(rule.Match); err != nil {
+			return fmt.Errorf("invalid 'match': %s", err)
+		}
+	}
+	switch rule.Layer {
+	case "", "above", "below":
+	default:
+		return fmt.Errorf("'%s' is not a valid layer", rule.Layer)
+	}
+	switch rule.Frame {
+	case "", "decor", "nada":
+	default:
+		return fmt.Errorf("'%s' is not a valid frame", rule.Frame)
+	}
+	if rule.Geometry != nil {
+		if len(rule.Geometry) != 4 {
+			return fmt.Errorf("'geometry' must have four values")
+		}
+		for i, v := range rule.Geometry {
+			n, err := geometryValue(v)
+			if err != nil {
+				return err
+			}
+			rule.Geometry[i] = n
+		}
+	}
+	return nil
+}
+
+// geometryValue converts a value of the 'geometry' of a rule, as decoded with
+// json.Decoder.UseNumber, to an int number of pixels or a float64 fraction.
+// Numbers written with a decimal point or an exponent are fractions, so 1 is
+// a pixel while 1.0 is the whole workspace.
+func geometryValue(v interface{}) (interface{}, error) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("'geometry' must only contain numbers")
+	}
+	if n, err := num.Int64(); err == nil {
+		if n < 0 {
+			return nil, fmt.Errorf("'geometry' must not be negative")
+		}
+		return int(n), nil
+	}
+	f, err := num.Float64()
+	if err != nil || f <= 0 || f > 1 {
+		return nil, fmt.Errorf("'%s' in 'geometry' is neither a number of "+
+			"pixels nor a fraction in the range (0, 1]", num)
+	}
+	return f, nil
+}
+
+// toInt converts a decoded JSON number to an int. Fractional values are
+// rejected here.
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("%s is not an integer", n)
+		}
+		return int(i), nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("%v is not an integer", n)
+		}
+		return int(n), nil
+	}
+	return 0, fmt.Errorf("expected an integer but got %s", jsonType(v))
+}
+
+// toStrings converts a decoded JSON array of strings to a string slice.
+func toStrings(v interface{}) ([]string, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list of strings but got %s",
+			jsonType(v))
+	}
+	strs := make([]string, len(list))
+	for i, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a list of strings but item "+
+				"%d is %s", i, jsonType(item))
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// jsonType returns a description of the JSON type of a decoded value for
+// use in error messages.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64, int, json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -50,13 +50,23 @@ type (
 		Instance  string
 	}
 	ChangedClientName struct {
-		Id xproto.Window
+		Id   xproto.Window
+		Name string
 	}
 	ChangedActiveClient struct {
 		Id xproto.Window
 	}
+	UrgentClient struct {
+		Id     xproto.Window
+		Urgent bool
+	}
 )
 
 type ChangedLayout struct {
 	Workspace string
+	Layout    string
+}
+
+type ChangedKeyMode struct {
+	Mode string
 }
@@ -42,6 +42,7 @@ var ewmhSupported = []string{
 	"_NET_WM_STATE_ABOVE",
 	"_NET_WM_STATE_BELOW",
 	"_NET_WM_STATE_FOCUSED",
+	"_NET_WM_STATE_DEMANDS_ATTENTION",
 	"_NET_WM_ALLOWED_ACTIONS",
 	"_NET_WM_ACTION_MOVE",
 	"_NET_WM_ACTION_RESIZE",
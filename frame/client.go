@@ -11,6 +11,7 @@ type Client interface {
 	State() int
 	Frame() Frame
 	IsMaximized() bool
+	IsUrgent() bool
 	Name() string
 	ClientGeom() xrect.Rect
 	ValidateHeight(height int) int
@@ -24,25 +24,35 @@ func NewDecor(X *xgbutil.XUtil,
 	}
 
 	df := &Decor{frame: f, theme: t}
+	df.createPieces()
 
-	df.topSide = df.newTopSide()
-	df.bottomSide = df.newBottomSide()
-	df.leftSide = df.newLeftSide()
-	df.rightSide = df.newRightSide()
+	return df, nil
+}
 
-	df.topLeft = df.newTopLeft()
-	df.topRight = df.newTopRight()
-	df.bottomLeft = df.newBottomLeft()
-	df.bottomRight = df.newBottomRight()
+// SetTheme replaces the theme of the frame and rebuilds its pieces in place.
+func (f *Decor) SetTheme(t *DecorTheme) {
+	f.destroyPieces()
+	f.theme = t
+	f.createPieces()
 
-	return df, nil
+	if f.Current() {
+		f.On()
+	}
 }
 
-func (f *Decor) Current() bool {
-	return f.client.Frame() == f
+func (f *Decor) createPieces() {
+	f.topSide = f.newTopSide()
+	f.bottomSide = f.newBottomSide()
+	f.leftSide = f.newLeftSide()
+	f.rightSide = f.newRightSide()
+
+	f.topLeft = f.newTopLeft()
+	f.topRight = f.newTopRight()
+	f.bottomLeft = f.newBottomLeft()
+	f.bottomRight = f.newBottomRight()
 }
 
-func (f *Decor) Destroy() {
+func (f *Decor) destroyPieces() {
 	f.topSide.Destroy()
 	f.bottomSide.Destroy()
 	f.leftSide.Destroy()
@@ -52,7 +62,14 @@ func (f *Decor) Destroy() {
 	f.topRight.Destroy()
 	f.bottomLeft.Destroy()
 	f.bottomRight.Destroy()
+}
 
+func (f *Decor) Current() bool {
+	return f.client.Frame() == f
+}
+
+func (f *Decor) Destroy() {
+	f.destroyPieces()
 	f.frame.Destroy()
 }
 
@@ -107,8 +124,14 @@ func (f *Decor) Active() {
 	f.parent.ClearAll()
 }
 
+// Inactive draws the inactive images, or the urgent ones if the client
+// demands attention.
 func (f *Decor) Inactive() {
 	f.State = Inactive
+	if f.client.IsUrgent() {
+		f.urgent()
+		return
+	}
 
 	f.topSide.Inactive()
 	f.bottomSide.Inactive()
@@ -124,6 +147,21 @@ func (f *Decor) Inactive() {
 	f.parent.ClearAll()
 }
 
+func (f *Decor) urgent() {
+	f.topSide.Urgent()
+	f.bottomSide.Urgent()
+	f.leftSide.Urgent()
+	f.rightSide.Urgent()
+
+	f.topLeft.Urgent()
+	f.topRight.Urgent()
+	f.bottomLeft.Urgent()
+	f.bottomRight.Urgent()
+
+	f.parent.Change(xproto.CwBackPixel, uint32(0xffffff))
+	f.parent.ClearAll()
+}
+
 func (f *Decor) Maximize() {
 	if f.theme.DecorSizeTop+f.theme.DecorSizeBottom+
 		f.theme.DecorSizeLeft+
@@ -223,16 +261,16 @@ func (f *Decor) Resize(validate bool, w, h int) {
 }
 
 type DecorTheme struct {
-	DecorTopA, DecorTopI                 *xgraphics.Image
-	DecorBottomA, DecorBottomI           *xgraphics.Image
-	DecorLeftA, DecorLeftI               *xgraphics.Image
-	DecorRightA, DecorRightI             *xgraphics.Image
-	DecorTopLeftA, DecorTopLeftI         *xgraphics.Image
-	DecorTopRightA, DecorTopRightI       *xgraphics.Image
-	DecorBottomLeftA, DecorBottomLeftI   *xgraphics.Image
-	DecorBottomRightA, DecorBottomRightI *xgraphics.Image
-	DecorSizeTop                         int
-	DecorSizeBottom                      int
-	DecorSizeLeft                        int
-	DecorSizeRight                       int
+	DecorTopA, DecorTopI, DecorTopU                         *xgraphics.Image
+	DecorBottomA, DecorBottomI, DecorBottomU                *xgraphics.Image
+	DecorLeftA, DecorLeftI, DecorLeftU                      *xgraphics.Image
+	DecorRightA, DecorRightI, DecorRightU                   *xgraphics.Image
+	DecorTopLeftA, DecorTopLeftI, DecorTopLeftU             *xgraphics.Image
+	DecorTopRightA, DecorTopRightI, DecorTopRightU          *xgraphics.Image
+	DecorBottomLeftA, DecorBottomLeftI, DecorBottomLeftU    *xgraphics.Image
+	DecorBottomRightA, DecorBottomRightI, DecorBottomRightU *xgraphics.Image
+	DecorSizeTop                                            int
+	DecorSizeBottom                                         int
+	DecorSizeLeft                                           int
+	DecorSizeRight                                          int
 }
@@ -42,10 +42,11 @@ func (f *Decor) newTopSide() *piece {
 
 	win := f.newPieceWindow("top", cursors.TopSide)
 	PixA, pixI := f.theme.DecorTopA, f.theme.DecorTopI
+	pixU := f.theme.DecorTopU
 
 	win.MROpt(fX|fY|fH, f.theme.DecorSizeTop, 0, 0, f.theme.DecorSizeTop)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newBottomSide() *piece {
@@ -55,10 +56,11 @@ func (f *Decor) newBottomSide() *piece {
 
 	win := f.newPieceWindow("bottom", cursors.BottomSide)
 	PixA, pixI := f.theme.DecorBottomA, f.theme.DecorBottomI
+	pixU := f.theme.DecorBottomU
 
 	win.MROpt(fX|fH, f.theme.DecorSizeBottom, 0, 0, f.theme.DecorSizeBottom)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newLeftSide() *piece {
@@ -68,10 +70,11 @@ func (f *Decor) newLeftSide() *piece {
 
 	win := f.newPieceWindow("left", cursors.LeftSide)
 	PixA, pixI := f.theme.DecorLeftA, f.theme.DecorLeftI
+	pixU := f.theme.DecorLeftU
 
 	win.MROpt(fX|fY|fW, 0, f.theme.DecorSizeLeft, f.theme.DecorSizeLeft, 0)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newRightSide() *piece {
@@ -81,10 +84,11 @@ func (f *Decor) newRightSide() *piece {
 
 	win := f.newPieceWindow("right", cursors.RightSide)
 	PixA, pixI := f.theme.DecorRightA, f.theme.DecorRightI
+	pixU := f.theme.DecorRightU
 
 	win.MROpt(fY|fW, 0, f.theme.DecorSizeRight, f.theme.DecorSizeRight, 0)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newTopLeft() *piece {
@@ -94,10 +98,11 @@ func (f *Decor) newTopLeft() *piece {
 
 	win := f.newPieceWindow("topleft", cursors.TopLeftCorner)
 	PixA, pixI := f.theme.DecorTopLeftA, f.theme.DecorTopLeftI
+	pixU := f.theme.DecorTopLeftU
 
 	win.MROpt(fX|fY|fW|fH, 0, 0, f.theme.DecorSizeTop, f.theme.DecorSizeLeft)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newTopRight() *piece {
@@ -107,10 +112,11 @@ func (f *Decor) newTopRight() *piece {
 
 	win := f.newPieceWindow("topright", cursors.TopRightCorner)
 	PixA, pixI := f.theme.DecorTopRightA, f.theme.DecorTopRightI
+	pixU := f.theme.DecorTopRightU
 
 	win.MROpt(fY|fW|fH, 0, 0, f.theme.DecorSizeTop, f.theme.DecorSizeRight)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newBottomLeft() *piece {
@@ -120,10 +126,11 @@ func (f *Decor) newBottomLeft() *piece {
 
 	win := f.newPieceWindow("bottomleft", cursors.BottomLeftCorner)
 	PixA, pixI := f.theme.DecorBottomLeftA, f.theme.DecorBottomLeftI
+	pixU := f.theme.DecorBottomLeftU
 
 	win.MROpt(fX|fW|fH, 0, 0, f.theme.DecorSizeBottom, f.theme.DecorSizeLeft)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
 
 func (f *Decor) newBottomRight() *piece {
@@ -133,8 +140,9 @@ func (f *Decor) newBottomRight() *piece {
 
 	win := f.newPieceWindow("bottomright", cursors.BottomRightCorner)
 	PixA, pixI := f.theme.DecorBottomRightA, f.theme.DecorBottomRightI
+	pixU := f.theme.DecorBottomRightU
 
 	win.MROpt(fW|fH, 0, 0, f.theme.DecorSizeBottom, f.theme.DecorSizeRight)
 
-	return newPiece(win, PixA, pixI)
+	return newPiece(win, PixA, pixI, pixU)
 }
@@ -9,24 +9,26 @@ import (
 
 type piece struct {
 	*xwindow.Window
-	active, inactive xproto.Pixmap
+	active, inactive, urgent xproto.Pixmap
 }
 
-func newPiece(w *xwindow.Window, active, inactive *xgraphics.Image) *piece {
+func newPiece(w *xwindow.Window,
+	active, inactive, urgent *xgraphics.Image) *piece {
+
 	p := &piece{Window: w}
-	p.Create(active, inactive)
+	p.Create(active, inactive, urgent)
 	return p
 }
 
 func newEmptyPiece() *piece {
-	return &piece{nil, 0, 0}
+	return &piece{nil, 0, 0, 0}
 }
 
 func (p *piece) empty() bool {
 	return p.Window == nil
 }
 
-func (p *piece) Create(act, inact *xgraphics.Image) {
+func (p *piece) Create(act, inact, urg *xgraphics.Image) {
 	if p.empty() {
 		return
 	}
@@ -48,6 +50,15 @@ func (p *piece) Create(act, inact *xgraphics.Image) {
 
 		p.inactive = inact.Pixmap
 	}
+	if urg != nil {
+		if p.urgent > 0 {
+			xgraphics.FreePixmap(p.X, p.urgent)
+		}
+		urg.CreatePixmap()
+		urg.XDraw()
+
+		p.urgent = urg.Pixmap
+	}
 }
 
 func (p *piece) Destroy() {
@@ -57,6 +68,7 @@ func (p *piece) Destroy() {
 	p.Window.Destroy() // detaches all event handlers
 	xgraphics.FreePixmap(p.X, p.active)
 	xgraphics.FreePixmap(p.X, p.inactive)
+	xgraphics.FreePixmap(p.X, p.urgent)
 }
 
 func (p *piece) Active() {
@@ -75,6 +87,14 @@ func (p *piece) Inactive() {
 	p.ClearAll()
 }
 
+func (p *piece) Urgent() {
+	if p.empty() {
+		return
+	}
+	p.Change(xproto.CwBackPixmap, uint32(p.urgent))
+	p.ClearAll()
+}
+
 func (p *piece) x() int {
 	if p.empty() {
 		return 0
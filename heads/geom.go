@@ -0,0 +1,50 @@
+package heads
+
+import (
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// ParsePos parses an x or y position in the workspace geometry geom. The
+// magic here is that while pos could just be a simple integer, which is a
+// number of pixels, it could also be a float greater than 0 but <= 1 in terms
+// of geom. pos must be an int or a float64.
+func ParsePos(geom xrect.Rect, pos interface{}, y bool) (int, bool) {
+	switch pos := pos.(type) {
+	case int:
+		return pos, true
+	case float64:
+		if pos <= 0 || pos > 1 {
+			logger.Warning.Printf("'%v' not in the valid range (0, 1].", pos)
+			return 0, false
+		}
+
+		if y {
+			return geom.Y() + int(float64(geom.Height())*pos), true
+		}
+		return geom.X() + int(float64(geom.Width())*pos), true
+	}
+	panic("unreachable")
+}
+
+// ParseDim parses a width or height dimension in the workspace geometry
+// geom. Like with ParsePos, dim is either an integer number of pixels or a
+// float greater than 0 but <= 1 in terms of geom.
+func ParseDim(geom xrect.Rect, dim interface{}, hght bool) (int, bool) {
+	switch dim := dim.(type) {
+	case int:
+		return dim, true
+	case float64:
+		if dim <= 0 || dim > 1 {
+			logger.Warning.Printf("'%v' not in the valid range (0, 1].", dim)
+			return 0, false
+		}
+
+		if hght {
+			return int(float64(geom.Height()) * dim), true
+		}
+		return int(float64(geom.Width()) * dim), true
+	}
+	panic("unreachable")
+}
@@ -3,6 +3,8 @@ package heads
 import (
 	"fmt"
 
+	"github.com/BurntSushi/xgb/randr"
+
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/xinerama"
@@ -19,6 +21,11 @@ type Heads struct {
 	geom     xinerama.Heads // Raw geometry of heads.
 	active   int            // Index in workarea/geom/visibles of active head.
 
+	// names holds the name of the RandR output of each head. randr is false
+	// if the RandR extension isn't available.
+	names []string
+	randr bool
+
 	Workspaces *workspace.Workspaces  // Slice of all available workspaces.
 	visibles   []*workspace.Workspace // Slice of all visible workspaces.
 }
@@ -28,12 +35,19 @@ func NewHeads(X *xgbutil.XUtil, defaultLayout string) *Heads {
 		X:      X,
 		active: 0,
 	}
+	if err := randr.Init(X.Conn()); err != nil {
+		logger.Warning.Printf("The X RandR extension could not be loaded, "+
+			"so workspaces can't be pinned to outputs: %s", err)
+	} else {
+		hds.randr = true
+	}
 	hds.Workspaces = workspace.NewWorkspaces(X, hds, defaultLayout)
 	return hds
 }
 
 func (hds *Heads) Initialize(clients Clients) {
 	hds.geom = query(hds.X)
+	hds.names = hds.outputNames(hds.geom)
 
 	// Check if the number of workspaces is less than the number of heads.
 	if len(hds.Workspaces.Wrks) < len(hds.geom) {
@@ -52,6 +66,7 @@ func (hds *Heads) Initialize(clients Clients) {
 	for i := 0; i < len(hds.geom); i++ {
 		hds.visibles[i] = hds.Workspaces.Wrks[i]
 	}
+	hds.active, _ = hds.pinVisibles(hds.visibles, hds.active, hds.names)
 
 	// Apply the struts set by clients to the workarea geometries.
 	// This will fill in the hds.workarea slice.
@@ -85,30 +100,33 @@ func (hds *Heads) Reload(clients Clients) {
 	// between the heads that were visible before and the heads that will
 	// be visible. If we have the same number of heads as before, then we
 	// don't much care about this.
-	if len(hds.visibles) < len(newGeom) {
+	// The new visibles are collected in newvis first, since the old ones
+	// are needed to hide the workspaces below.
+	oldActive := hds.visibles[hds.active]
+	newvis := make([]*workspace.Workspace, len(newGeom))
+	newActive := -1
+	if len(hds.visibles) <= len(newGeom) {
 		// We have more heads than we had before. So let's just expand our
 		// visibles with some new workspaces. Remember, we're guaranteed to
 		// have at least as many workspaces as heads.
 		// We also leave the currently active workspace alone.
+		copy(newvis, hds.visibles)
+		newActive = hds.active
 		for i := len(hds.visibles); i < len(newGeom); i++ {
 			// Find an available (i.e., hidden) workspace.
 			for _, wrk := range hds.Workspaces.Wrks {
-				if hds.VisibleIndex(wrk) == -1 {
-					hds.visibles = append(hds.visibles, wrk)
+				if visibleIndex(newvis, wrk) == -1 {
+					newvis[i] = wrk
 					break
 				}
 			}
 		}
-		hds.geom = newGeom
-	} else if len(hds.visibles) > len(newGeom) {
+	} else {
 		// We now have fewer heads than we had before, so we'll reconstruct
 		// our list of visibles, with care to keep the same ordering and to
 		// keep the currently workspace still visible. (I believe this behavior
 		// to be the least surprising to the user.)
-		oldActive := hds.visibles[hds.active]
 		oldvis := hds.visibles
-		newvis := make([]*workspace.Workspace, len(newGeom))
-		newActive := -1
 		newi := 0
 		for oldi := 0; oldi < len(oldvis) && newi < len(newvis); oldi++ {
 			// We always add this workspace, UNLESS we have only one spot left
@@ -126,28 +144,32 @@ func (hds *Heads) Reload(clients Clients) {
 			newi++
 		}
 
-		// Now that we've collected our new visibles list, we need to hide
-		// all of the workspaces. (We'll show them later.) This is so that
-		// they get properly refreshed into the right locations on the screen.
-		for _, wrk := range hds.Workspaces.Wrks {
-			wrk.Hide()
-		}
-		hds.visibles = newvis
-		hds.geom = newGeom
-		hds.ActivateWorkspace(hds.visibles[newActive])
-
-		if oldActive != hds.visibles[hds.active] {
+		if oldActive != newvis[newActive] {
 			panic(fmt.Sprintf("BUG: Old active workspace %s is not the same "+
-				"as the new active workspace.",
-				oldActive, hds.visibles[hds.active]))
+				"as the new active workspace %s.",
+				oldActive, newvis[newActive]))
 		}
 	}
 
-	// Protect my sanity...
-	if len(hds.visibles) != len(hds.geom) {
-		panic(fmt.Sprintf("BUG: length of visibles (%d) != length of "+
-			"geometry (%d)", len(hds.visibles), len(hds.geom)))
+	// The outputs may have changed, so workspaces that are pinned to an
+	// output move to it.
+	newNames := hds.outputNames(newGeom)
+	newActive, pinned := hds.pinVisibles(newvis, newActive, newNames)
+
+	// If the old heads show other workspaces now, we need to hide all of the
+	// workspaces. (We'll show them later.) This is so that they get properly
+	// refreshed into the right locations on the screen. It happens before
+	// the new visibles are in place, so that the state of every client is
+	// saved relative to the head it was on.
+	if len(newvis) < len(hds.visibles) || pinned {
+		for _, wrk := range hds.Workspaces.Wrks {
+			wrk.Hide()
+		}
 	}
+	hds.visibles = newvis
+	hds.geom = newGeom
+	hds.names = newNames
+	hds.active = newActive
 
 	// Apply the struts set by clients to the workarea geometries.
 	// This will fill in the hds.workarea slice.
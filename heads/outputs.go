@@ -0,0 +1,133 @@
+package heads
+
+import (
+	"strings"
+
+	"github.com/BurntSushi/xgb/randr"
+
+	"github.com/BurntSushi/xgbutil/xinerama"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/logger"
+	"github.com/onodera-punpun/sponewm/workspace"
+)
+
+// outputNames returns the name of the RandR output, e.g., "DP-1", that shows
+// each head in geom. Outputs are matched to heads by their geometry. Heads
+// without a matching output, which is every head when the RandR extension
+// isn't available, get an empty name.
+func (hds *Heads) outputNames(geom xinerama.Heads) []string {
+	names := make([]string, len(geom))
+	if !hds.randr {
+		return names
+	}
+
+	conn := hds.X.Conn()
+	res, err := randr.GetScreenResourcesCurrent(conn, hds.X.RootWin()).Reply()
+	if err != nil {
+		logger.Warning.Printf("Could not get the RandR outputs: %s", err)
+		return names
+	}
+	for _, output := range res.Outputs {
+		info, err := randr.GetOutputInfo(
+			conn, output, res.ConfigTimestamp).Reply()
+		if err != nil || info.Connection != randr.ConnectionConnected ||
+			info.Crtc == 0 {
+
+			continue
+		}
+		crtc, err := randr.GetCrtcInfo(
+			conn, info.Crtc, res.ConfigTimestamp).Reply()
+		if err != nil {
+			continue
+		}
+
+		// Mirrored outputs show the same head, which gets the first name.
+		for i, hd := range geom {
+			if len(names[i]) == 0 &&
+				hd.X() == int(crtc.X) && hd.Y() == int(crtc.Y) &&
+				hd.Width() == int(crtc.Width) &&
+				hd.Height() == int(crtc.Height) {
+
+				names[i] = string(info.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// PinnedHead returns the index of the head that shows the output wrk is
+// pinned to with the 'workspaceoutputs' setting. -1 is returned if wrk isn't
+// pinned, or if its output isn't connected, in which case it may be shown on
+// any head.
+func (hds *Heads) PinnedHead(wrk *workspace.Workspace) int {
+	return pinnedHead(wrk, hds.names)
+}
+
+// pinnedHead is like PinnedHead, but for the heads whose outputs are names.
+func pinnedHead(wrk *workspace.Workspace, names []string) int {
+	output := ""
+	for name, out := range config.Settings.WorkspaceOutputs {
+		if strings.EqualFold(name, wrk.Name) {
+			output = out
+			break
+		}
+	}
+	if len(output) == 0 {
+		return -1
+	}
+	for i, name := range names {
+		if name == output {
+			return i
+		}
+	}
+	return -1
+}
+
+// pinVisibles puts every head in visibles whose output in names has a
+// workspace pinned to it on that workspace, unless it shows such a workspace
+// already. active is the index of the active head in visibles. The index of
+// the head that shows the same workspace afterwards, or the same index if
+// that workspace was replaced, is returned along with whether visibles
+// changed.
+func (hds *Heads) pinVisibles(visibles []*workspace.Workspace, active int,
+	names []string) (int, bool) {
+
+	activeWrk := visibles[active]
+	changed := false
+	for i := range visibles {
+		if pinnedHead(visibles[i], names) == i {
+			continue
+		}
+		for _, wrk := range hds.Workspaces.Wrks {
+			if pinnedHead(wrk, names) != i {
+				continue
+			}
+			if vi := visibleIndex(visibles, wrk); vi > -1 {
+				visibles[vi] = visibles[i]
+			}
+			visibles[i] = wrk
+			changed = true
+			break
+		}
+	}
+
+	if vi := visibleIndex(visibles, activeWrk); vi > -1 {
+		active = vi
+	}
+	return active, changed
+}
+
+// visibleIndex returns the index of wrk in visibles, or -1 if it isn't
+// there.
+func visibleIndex(visibles []*workspace.Workspace,
+	wrk *workspace.Workspace) int {
+
+	for i, vwrk := range visibles {
+		if vwrk == wrk {
+			return i
+		}
+	}
+	return -1
+}
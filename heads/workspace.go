@@ -55,6 +55,10 @@ func (hds *Heads) AddWorkspace(wk *workspace.Workspace) {
 	hds.Workspaces.Add(wk)
 }
 
+func (hds *Heads) MoveWorkspace(wk *workspace.Workspace, i int) {
+	hds.Workspaces.Move(wk, i)
+}
+
 func (hds *Heads) RemoveWorkspace(wk *workspace.Workspace) {
 	// Don't allow it if this would result in fewer workspaces than there
 	// are active physical heads.
@@ -68,18 +72,33 @@ func (hds *Heads) RemoveWorkspace(wk *workspace.Workspace) {
 	}
 
 	if wk.IsVisible() {
-		// Find the last-most hidden workspace that is not itself and switch.
-		for i := len(hds.Workspaces.Wrks) - 1; i >= 0; i-- {
-			work := hds.Workspaces.Wrks[i]
-			if work != wk && !work.IsVisible() {
-				hds.SwitchWorkspaces(wk, work)
-				break
-			}
+		work := hds.Replacement(wk)
+		if work == nil {
+			panic(fmt.Sprintf("No workspace can replace '%s'.", wk))
 		}
+		hds.SwitchWorkspaces(wk, work)
 	}
 	hds.Workspaces.Remove(wk)
 }
 
+// Replacement returns the workspace that is shown in place of the visible
+// workspace wk when wk is removed. This is the last-most hidden workspace
+// that isn't pinned to the output of another head. nil is returned if there
+// is no such workspace.
+func (hds *Heads) Replacement(wk *workspace.Workspace) *workspace.Workspace {
+	head := hds.VisibleIndex(wk)
+	for i := len(hds.Workspaces.Wrks) - 1; i >= 0; i-- {
+		work := hds.Workspaces.Wrks[i]
+		if work == wk || work.IsVisible() {
+			continue
+		}
+		if pinned := hds.PinnedHead(work); pinned == -1 || pinned == head {
+			return work
+		}
+	}
+	return nil
+}
+
 func (hds *Heads) ActiveWorkspace() *workspace.Workspace {
 	return hds.visibles[hds.active]
 }
@@ -0,0 +1,448 @@
+package layout
+
+import (
+	"container/list"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+// Directions in which a Bsp layout can split the focused client for the next
+// client that is added.
+const (
+	BspNone = iota
+	BspLeft
+	BspRight
+	BspUp
+	BspDown
+)
+
+const (
+	bspRatioMin = 0.1
+	bspRatioMax = 0.9
+)
+
+// bspNode is a node in the tree of a Bsp layout. Leaves hold a client, while
+// every other node has exactly two children that share its area.
+type bspNode struct {
+	parent        *bspNode
+	first, second *bspNode
+	client        Client
+
+	// vertical is true when the node is split by a vertical line, i.e., when
+	// its first child is on the left and its second child on the right.
+	// Otherwise the first child is on top of the second one.
+	vertical bool
+
+	// ratio is the fraction of the node's area that goes to the first child.
+	ratio float64
+
+	// The area covered by the node when the layout was placed last.
+	x, y, width, height int
+}
+
+func (n *bspNode) isLeaf() bool {
+	return n.client != nil
+}
+
+// sibling returns the other child of the node's parent.
+func (n *bspNode) sibling() *bspNode {
+	if n.parent.first == n {
+		return n.parent.second
+	}
+	return n.parent.first
+}
+
+// replace puts other in the place of n in the tree.
+func (n *bspNode) replace(other *bspNode) {
+	other.parent = n.parent
+	if n.parent != nil {
+		if n.parent.first == n {
+			n.parent.first = other
+		} else {
+			n.parent.second = other
+		}
+	}
+}
+
+// swapChildren exchanges the children of the node while keeping the size of
+// each child.
+func (n *bspNode) swapChildren() {
+	n.first, n.second = n.second, n.first
+	n.ratio = 1 - n.ratio
+}
+
+// Bsp is an i3/bspwm-like layout that keeps its clients in a binary tree of
+// splits. A new client splits the area of the focused client in two, in the
+// preselected direction if there is one, and along the longer side
+// otherwise. The tree isn't changed by switching the workspace to a floating
+// layout, so the splits are the same when the workspace is tiled again.
+type Bsp struct {
+	root *bspNode
+	geom xrect.Rect
+
+	// last is the leaf added most recently, which is split when no client in
+	// the layout is focused.
+	last *bspNode
+
+	// presel and preselRatio are used for the next client that is added.
+	// preselRatio is the fraction of the split area that the new client gets.
+	presel      int
+	preselRatio float64
+}
+
+func NewBsp() *Bsp {
+	return &Bsp{
+		presel:      BspNone,
+		preselRatio: 0.5,
+	}
+}
+
+func (b *Bsp) Name() string {
+	return "Bsp"
+}
+
+func (b *Bsp) SetGeom(geom xrect.Rect) {
+	b.geom = geom
+}
+
+func (b *Bsp) Place() {
+	if b.geom == nil || b.root == nil {
+		return
+	}
+
+	gap := config.Settings.Gap
+	padding := config.Settings.TilePadding - (gap / 2)
+
+	x := b.geom.X() + padding
+	y := b.geom.Y() + padding
+	width := b.geom.Width() - (padding * 2)
+	height := b.geom.Height() - (padding * 2)
+
+	b.place(b.root, x, y, width, height)
+}
+
+func (b *Bsp) place(n *bspNode, x, y, width, height int) {
+	n.x, n.y, n.width, n.height = x, y, width, height
+	if n.isLeaf() {
+		gap := config.Settings.Gap
+
+		n.client.FrameTile()
+		n.client.MoveResize(x+(gap/2), y+(gap/2), width-gap, height-gap)
+		return
+	}
+
+	if n.vertical {
+		w := int(float64(width) * n.ratio)
+		b.place(n.first, x, y, w, height)
+		b.place(n.second, x+w, y, width-w, height)
+	} else {
+		h := int(float64(height) * n.ratio)
+		b.place(n.first, x, y, width, h)
+		b.place(n.second, x, y+h, width, height-h)
+	}
+}
+
+func (b *Bsp) Unplace() {}
+
+// Add splits the focused client, or the client added last, to make room for
+// c. The preselection, if any, is used up.
+func (b *Bsp) Add(c Client) {
+	if b.Exists(c) {
+		return
+	}
+
+	leaf := &bspNode{client: c}
+	target := b.focused()
+	if target == nil {
+		b.root = leaf
+		b.last = leaf
+		return
+	}
+
+	dir := b.presel
+	ratio := b.preselRatio
+	if dir == BspNone {
+		dir = BspDown
+		if target.width >= target.height {
+			dir = BspRight
+		}
+	}
+	b.presel, b.preselRatio = BspNone, 0.5
+
+	split := &bspNode{
+		vertical: dir == BspLeft || dir == BspRight,
+	}
+	target.replace(split)
+	if target == b.root {
+		b.root = split
+	}
+	if dir == BspLeft || dir == BspUp {
+		split.first, split.second = leaf, target
+		split.ratio = ratio
+	} else {
+		split.first, split.second = target, leaf
+		split.ratio = 1 - ratio
+	}
+	target.parent, leaf.parent = split, split
+	b.last = leaf
+}
+
+func (b *Bsp) Remove(c Client) {
+	n := b.find(c)
+	if n == nil {
+		return
+	}
+	if n == b.last {
+		b.last = nil
+	}
+	if n.parent == nil {
+		b.root = nil
+		return
+	}
+
+	sibling := n.sibling()
+	parent := n.parent
+	parent.replace(sibling)
+	if parent == b.root {
+		b.root = sibling
+	}
+}
+
+func (b *Bsp) Exists(c Client) bool {
+	return b.find(c) != nil
+}
+
+// find returns the leaf holding c, or nil if c isn't in the layout.
+func (b *Bsp) find(c Client) *bspNode {
+	var found *bspNode
+	b.eachLeaf(func(n *bspNode) {
+		if n.client == c {
+			found = n
+		}
+	})
+	return found
+}
+
+// focused returns the leaf that is split by the next client that is added.
+func (b *Bsp) focused() *bspNode {
+	var active *bspNode
+	b.eachLeaf(func(n *bspNode) {
+		if n.client.IsActive() {
+			active = n
+		}
+	})
+	switch {
+	case active != nil:
+		return active
+	case b.last != nil:
+		return b.last
+	}
+
+	// Without a hint, split the bottom right most client.
+	n := b.root
+	for n != nil && !n.isLeaf() {
+		n = n.second
+	}
+	return n
+}
+
+// eachLeaf calls f for every leaf of the tree, from left to right.
+func (b *Bsp) eachLeaf(f func(n *bspNode)) {
+	var walk func(n *bspNode)
+	walk = func(n *bspNode) {
+		if n == nil {
+			return
+		}
+		if n.isLeaf() {
+			f(n)
+			return
+		}
+		walk(n.first)
+		walk(n.second)
+	}
+	walk(b.root)
+}
+
+func (b *Bsp) Destroy() {}
+
+func (b *Bsp) Save() {}
+
+func (b *Bsp) Reposition() {}
+
+func (b *Bsp) MROpt(c Client, flags, x, y, width, height int) {}
+
+// MoveResize moves the split borders next to c when it is resized with the
+// pointer, so that c gets the requested geometry as far as the tree allows.
+// Requests that don't come from the pointer are ignored, since they'd
+// otherwise undo the layout.
+func (b *Bsp) MoveResize(c Client, x, y, width, height int) {
+	n := b.find(c)
+	if n == nil || c.DragGeom() == nil {
+		return
+	}
+
+	// The requested geometry is the one of the frame, which is inset by half
+	// of the gap on every side of the area of the leaf.
+	gap := config.Settings.Gap
+	geom := c.Geom()
+	if x != geom.X() {
+		b.moveBorder(n, true, false, x-(gap/2))
+	}
+	if x+width != geom.X()+geom.Width() {
+		b.moveBorder(n, true, true, x+width+gap-(gap/2))
+	}
+	if y != geom.Y() {
+		b.moveBorder(n, false, false, y-(gap/2))
+	}
+	if y+height != geom.Y()+geom.Height() {
+		b.moveBorder(n, false, true, y+height+gap-(gap/2))
+	}
+	b.Place()
+}
+
+// moveBorder moves the closest split border on one side of n to pos. The
+// border is vertical when vertical is true, and it is after (to the right of
+// or below) n when after is true.
+func (b *Bsp) moveBorder(n *bspNode, vertical, after bool, pos int) {
+	for ; n.parent != nil; n = n.parent {
+		p := n.parent
+		if p.vertical != vertical || (p.first == n) != after {
+			continue
+		}
+
+		start, length := p.y, p.height
+		if vertical {
+			start, length = p.x, p.width
+		}
+		if length <= 0 {
+			return
+		}
+		p.ratio = float64(pos-start) / float64(length)
+		if p.ratio < bspRatioMin {
+			p.ratio = bspRatioMin
+		} else if p.ratio > bspRatioMax {
+			p.ratio = bspRatioMax
+		}
+		return
+	}
+}
+
+func (b *Bsp) Move(c Client, x, y int) {}
+
+func (b *Bsp) Resize(c Client, width, height int) {}
+
+// MakeMaster swaps c with the client in the top left most leaf.
+func (b *Bsp) MakeMaster(c Client) {
+	n := b.find(c)
+	if n == nil {
+		return
+	}
+	master := b.root
+	for !master.isLeaf() {
+		master = master.first
+	}
+	n.client, master.client = master.client, n.client
+	b.Place()
+}
+
+// Preselect sets the direction in which the focused client is split when the
+// next client is added, and the fraction of the area that the new client
+// gets. The direction BspNone cancels the preselection.
+func (b *Bsp) Preselect(dir int, ratio float64) {
+	if ratio < bspRatioMin {
+		ratio = bspRatioMin
+	} else if ratio > bspRatioMax {
+		ratio = bspRatioMax
+	}
+	b.presel, b.preselRatio = dir, ratio
+}
+
+// Rotate turns the subtree that contains c and its sibling by 90 degrees
+// clockwise, so that every vertical split becomes horizontal and vice versa.
+func (b *Bsp) Rotate(c Client) {
+	n := b.find(c)
+	if n == nil || n.parent == nil {
+		return
+	}
+
+	var rotate func(n *bspNode)
+	rotate = func(n *bspNode) {
+		if n.isLeaf() {
+			return
+		}
+		// Turning clockwise moves the left child to the top and the bottom
+		// child to the left.
+		if !n.vertical {
+			n.swapChildren()
+		}
+		n.vertical = !n.vertical
+		rotate(n.first)
+		rotate(n.second)
+	}
+	rotate(n.parent)
+	b.Place()
+}
+
+// Flip mirrors the subtree that contains c and its sibling. When horizontal
+// is true, left and right are swapped. Otherwise top and bottom are swapped.
+func (b *Bsp) Flip(c Client, horizontal bool) {
+	n := b.find(c)
+	if n == nil || n.parent == nil {
+		return
+	}
+
+	var flip func(n *bspNode)
+	flip = func(n *bspNode) {
+		if n.isLeaf() {
+			return
+		}
+		if n.vertical == horizontal {
+			n.swapChildren()
+		}
+		flip(n.first)
+		flip(n.second)
+	}
+	flip(n.parent)
+	b.Place()
+}
+
+// leaves returns the clients of the layout from left to right.
+func (b *Bsp) leaves() *list.List {
+	clients := list.New()
+	b.eachLeaf(func(n *bspNode) {
+		clients.PushBack(n.client)
+	})
+	return clients
+}
+
+func (b *Bsp) FocusNext() {
+	cycleFocus(b.leaves(), true)
+}
+
+func (b *Bsp) FocusPrev() {
+	cycleFocus(b.leaves(), false)
+}
+
+// Replace puts new in the leaf of old. If new is in the tree already, its
+// own leaf is removed first.
+func (b *Bsp) Replace(old, new Client) {
+	if b.find(old) == nil {
+		return
+	}
+	b.Remove(new)
+	n := b.find(old)
+	n.client = new
+}
+
+// Swap exchanges the leaves of c1 and c2, so each client gets the area of
+// the other.
+func (b *Bsp) Swap(c1, c2 Client) {
+	n1, n2 := b.find(c1), b.find(c2)
+	if n1 == nil || n2 == nil {
+		return
+	}
+	n1.client, n2.client = c2, c1
+	b.Place()
+}
@@ -22,6 +22,8 @@ type Client interface {
 	Focus()
 	Raise()
 	IsActive() bool
+	IsMapped() bool
+	Iconified() bool
 
 	MROpt(validate bool, flags, x, y, width, height int)
 	MoveResize(x, y, width, height int)
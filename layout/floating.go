@@ -103,7 +103,7 @@ func (f *Floating) Resize(c Client, width, height int) {
 }
 
 func (f *Floating) InitialPlacement(c Client, X *xgbutil.XUtil) {
-	padding := config.SettingsVal["floatpadding"].(int)
+	padding := config.Settings.FloatPadding
 
 	cgeom := c.Geom()
 	qp, _ := xproto.QueryPointer(X.Conn(), X.RootWin()).Reply()
@@ -127,3 +127,26 @@ func (f *Floating) InitialPlacement(c Client, X *xgbutil.XUtil) {
 
 	f.Move(c, x, y)
 }
+
+func (f *Floating) FocusNext() {
+	cycleFocus(f.clients, true)
+}
+
+func (f *Floating) FocusPrev() {
+	cycleFocus(f.clients, false)
+}
+
+func (f *Floating) Replace(old, new Client) {
+	replaceInList(f.clients, old, new)
+}
+
+// Swap exchanges the geometries of c1 and c2.
+func (f *Floating) Swap(c1, c2 Client) {
+	if !f.Exists(c1) || !f.Exists(c2) {
+		return
+	}
+	x1, y1, w1, h1 := xrect.Pieces(c1.Geom())
+	x2, y2, w2, h2 := xrect.Pieces(c2.Geom())
+	f.MoveResize(c1, x2, y2, w2, h2)
+	f.MoveResize(c2, x1, y1, w1, h1)
+}
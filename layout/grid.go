@@ -0,0 +1,141 @@
+package layout
+
+import (
+	"container/list"
+	"math"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+// Grid is a layout that places clients in rows of equal cells. The number of
+// columns is chosen so that the grid is as close to square as possible. When
+// the last row isn't full, its clients share the whole width of the row.
+type Grid struct {
+	clients *list.List
+	geom    xrect.Rect
+}
+
+func NewGrid() *Grid {
+	return &Grid{
+		clients: list.New(),
+	}
+}
+
+func (g *Grid) Name() string {
+	return "Grid"
+}
+
+func (g *Grid) SetGeom(geom xrect.Rect) {
+	g.geom = geom
+}
+
+func (g *Grid) Place() {
+	if g.geom == nil {
+		return
+	}
+
+	n := g.clients.Len()
+	if n == 0 {
+		return
+	}
+
+	gap := config.Settings.Gap
+	padding := config.Settings.TilePadding - (gap / 2)
+
+	x := g.geom.X() + padding
+	y := g.geom.Y() + padding
+	width := g.geom.Width() - (padding * 2)
+	height := g.geom.Height() - (padding * 2)
+
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	rows := (n + cols - 1) / cols
+
+	i := 0
+	for l := g.clients.Front(); l != nil; l = l.Next() {
+		c := l.Value.(Client)
+
+		row, col := i/cols, i%cols
+		count := cols
+		if row == rows-1 {
+			count = n - row*cols
+		}
+
+		cx, cw := split(x, width, col, count)
+		cy, ch := split(y, height, row, rows)
+
+		c.FrameTile()
+		c.MoveResize(cx+(gap/2), cy+(gap/2), cw-gap, ch-gap)
+		i++
+	}
+}
+
+func (g *Grid) Unplace() {}
+
+func (g *Grid) Add(c Client) {
+	if !g.Exists(c) {
+		g.clients.PushBack(c)
+	}
+}
+
+func (g *Grid) Remove(c Client) {
+	for l := g.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			g.clients.Remove(l)
+			return
+		}
+	}
+}
+
+func (g *Grid) Exists(c Client) bool {
+	for l := g.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Grid) Destroy() {}
+
+func (g *Grid) Save() {}
+
+func (g *Grid) Reposition() {}
+
+func (g *Grid) MROpt(c Client, flags, x, y, width, height int) {}
+
+func (g *Grid) MoveResize(c Client, x, y, width, height int) {}
+
+func (g *Grid) Move(c Client, x, y int) {}
+
+func (g *Grid) Resize(c Client, width, height int) {}
+
+// MakeMaster moves the client to the top left cell of the grid.
+func (g *Grid) MakeMaster(c Client) {
+	for l := g.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			g.clients.MoveToFront(l)
+			g.Place()
+			return
+		}
+	}
+}
+
+func (g *Grid) FocusNext() {
+	cycleFocus(g.clients, true)
+}
+
+func (g *Grid) FocusPrev() {
+	cycleFocus(g.clients, false)
+}
+
+func (g *Grid) Replace(old, new Client) {
+	replaceInList(g.clients, old, new)
+}
+
+func (g *Grid) Swap(c1, c2 Client) {
+	if swapInList(g.clients, c1, c2) {
+		g.Place()
+	}
+}
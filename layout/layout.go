@@ -1,6 +1,8 @@
 package layout
 
 import (
+	"container/list"
+
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/xrect"
 )
@@ -36,3 +38,99 @@ type Tiler interface {
 	Layout
 	MakeMaster(c Client)
 }
+
+// Cycler is implemented by layouts that keep their clients in an order that
+// focus can be cycled through.
+type Cycler interface {
+	FocusNext()
+	FocusPrev()
+}
+
+// cycleFocus focuses and raises the client that comes after (or before) the
+// active client in clients. It wraps around at either end of the list. When
+// no client in the list is active, the first (or last) client is focused.
+// Clients that are iconified or not mapped are skipped.
+func cycleFocus(clients *list.List, forward bool) {
+	visible := make([]Client, 0, clients.Len())
+	active := -1
+	for l := clients.Front(); l != nil; l = l.Next() {
+		c := l.Value.(Client)
+		if c.Iconified() || !c.IsMapped() {
+			continue
+		}
+		if c.IsActive() {
+			active = len(visible)
+		}
+		visible = append(visible, c)
+	}
+	if len(visible) == 0 {
+		return
+	}
+
+	var next int
+	switch {
+	case active == -1 && forward:
+		next = 0
+	case active == -1:
+		next = len(visible) - 1
+	case forward:
+		next = (active + 1) % len(visible)
+	default:
+		next = (active - 1 + len(visible)) % len(visible)
+	}
+
+	c := visible[next]
+	c.Focus()
+	c.Raise()
+}
+
+// Replacer is implemented by layouts that can put a client in the place of
+// another one, e.g., when a terminal is swallowed by a program it started.
+type Replacer interface {
+	Replace(old, new Client)
+}
+
+// replaceInList puts new in the place of old in clients. If new is in the
+// list already, it is moved. Nothing happens if old isn't in the list.
+func replaceInList(clients *list.List, old, new Client) {
+	var oldElem, newElem *list.Element
+	for l := clients.Front(); l != nil; l = l.Next() {
+		switch l.Value.(Client) {
+		case old:
+			oldElem = l
+		case new:
+			newElem = l
+		}
+	}
+	if oldElem == nil {
+		return
+	}
+	if newElem != nil {
+		clients.Remove(newElem)
+	}
+	oldElem.Value = new
+}
+
+// Swapper is implemented by layouts in which two clients can trade places.
+type Swapper interface {
+	Swap(c1, c2 Client)
+}
+
+// swapInList exchanges the positions of c1 and c2 in clients. It returns
+// false, and leaves the list alone, unless both clients are in it.
+func swapInList(clients *list.List, c1, c2 Client) bool {
+	var e1, e2 *list.Element
+	for l := clients.Front(); l != nil; l = l.Next() {
+		switch l.Value.(Client) {
+		case c1:
+			e1 = l
+		case c2:
+			e2 = l
+		}
+	}
+	if e1 == nil || e2 == nil {
+		return false
+	}
+	e1.Value, e2.Value = c2, c1
+	return true
+}
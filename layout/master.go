@@ -0,0 +1,246 @@
+package layout
+
+import (
+	"container/list"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+// Orientations of the master area in a MasterStack layout.
+const (
+	MasterLeft = iota
+	MasterRight
+	MasterTop
+	MasterBottom
+)
+
+const (
+	masterRatioMin  = 0.1
+	masterRatioMax  = 0.9
+	masterRatioStep = 0.05
+)
+
+// MasterArea holds the number of clients in the master area of a MasterStack
+// layout and the fraction of the workspace covered by the master area.
+// A single MasterArea is shared by every MasterStack layout of a workspace,
+// so that switching orientations keeps the values.
+type MasterArea struct {
+	Count int
+	Ratio float64
+}
+
+func NewMasterArea() *MasterArea {
+	return &MasterArea{
+		Count: 1,
+		Ratio: 0.55,
+	}
+}
+
+// CountAdd adds n (which may be negative) to the number of master clients.
+// The count never drops below zero, in which case every client is stacked.
+func (ma *MasterArea) CountAdd(n int) {
+	ma.Count += n
+	if ma.Count < 0 {
+		ma.Count = 0
+	}
+}
+
+// Grow makes the master area a step bigger.
+func (ma *MasterArea) Grow() {
+	ma.Ratio += masterRatioStep
+	if ma.Ratio > masterRatioMax {
+		ma.Ratio = masterRatioMax
+	}
+}
+
+// Shrink makes the master area a step smaller.
+func (ma *MasterArea) Shrink() {
+	ma.Ratio -= masterRatioStep
+	if ma.Ratio < masterRatioMin {
+		ma.Ratio = masterRatioMin
+	}
+}
+
+// MasterStack is a dwm-style layout. The first Count clients are placed in
+// the master area, which covers Ratio of the workspace on the side given by
+// the orientation. The remaining clients share the stack area.
+type MasterStack struct {
+	clients *list.List
+	geom    xrect.Rect
+	orient  int
+	area    *MasterArea
+}
+
+func NewMasterStack(orient int, area *MasterArea) *MasterStack {
+	return &MasterStack{
+		clients: list.New(),
+		orient:  orient,
+		area:    area,
+	}
+}
+
+func (ms *MasterStack) Name() string {
+	switch ms.orient {
+	case MasterRight:
+		return "MasterRight"
+	case MasterTop:
+		return "MasterTop"
+	case MasterBottom:
+		return "MasterBottom"
+	}
+	return "MasterLeft"
+}
+
+// MasterArea returns the master count and ratio used by this layout.
+func (ms *MasterStack) MasterArea() *MasterArea {
+	return ms.area
+}
+
+func (ms *MasterStack) SetGeom(geom xrect.Rect) {
+	ms.geom = geom
+}
+
+func (ms *MasterStack) Place() {
+	if ms.geom == nil {
+		return
+	}
+
+	gap := config.Settings.Gap
+	padding := config.Settings.TilePadding - (gap / 2)
+
+	x := ms.geom.X() + padding
+	y := ms.geom.Y() + padding
+	width := ms.geom.Width() - (padding * 2)
+	height := ms.geom.Height() - (padding * 2)
+
+	n := ms.clients.Len()
+	masters := ms.area.Count
+	if masters > n {
+		masters = n
+	}
+	stacked := n - masters
+
+	// The master area is split off along the horizontal axis for left and
+	// right orientations, and along the vertical axis otherwise.
+	vertical := ms.orient == MasterLeft || ms.orient == MasterRight
+	size := width
+	if !vertical {
+		size = height
+	}
+	msize := size
+	if masters == 0 {
+		msize = 0
+	} else if stacked > 0 {
+		msize = int(float64(size) * ms.area.Ratio)
+	}
+
+	// The offsets of the master and stack areas along the split axis.
+	moff, soff := 0, msize
+	if ms.orient == MasterRight || ms.orient == MasterBottom {
+		moff, soff = size-msize, 0
+	}
+
+	i := 0
+	for l := ms.clients.Front(); l != nil; l = l.Next() {
+		c := l.Value.(Client)
+
+		off, asize, index, count := moff, msize, i, masters
+		if i >= masters {
+			off, asize, index, count = soff, size-msize, i-masters, stacked
+		}
+
+		var cx, cy, cw, ch int
+		if vertical {
+			cy, ch = split(y, height, index, count)
+			cx, cw = x+off, asize
+		} else {
+			cx, cw = split(x, width, index, count)
+			cy, ch = y+off, asize
+		}
+
+		c.FrameTile()
+		c.MoveResize(cx+(gap/2), cy+(gap/2), cw-gap, ch-gap)
+		i++
+	}
+}
+
+// split divides the segment starting at start with the given length into
+// count equal parts, and returns the start and length of the part at index.
+// The last part absorbs any remainder.
+func split(start, length, index, count int) (int, int) {
+	part := length / count
+	if index == count-1 {
+		return start + index*part, length - index*part
+	}
+	return start + index*part, part
+}
+
+func (ms *MasterStack) Unplace() {}
+
+func (ms *MasterStack) Add(c Client) {
+	if !ms.Exists(c) {
+		ms.clients.PushBack(c)
+	}
+}
+
+func (ms *MasterStack) Remove(c Client) {
+	for l := ms.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			ms.clients.Remove(l)
+			return
+		}
+	}
+}
+
+func (ms *MasterStack) Exists(c Client) bool {
+	for l := ms.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (ms *MasterStack) Destroy() {}
+
+func (ms *MasterStack) Save() {}
+
+func (ms *MasterStack) Reposition() {}
+
+func (ms *MasterStack) MROpt(c Client, flags, x, y, width, height int) {}
+
+func (ms *MasterStack) MoveResize(c Client, x, y, width, height int) {}
+
+func (ms *MasterStack) Move(c Client, x, y int) {}
+
+func (ms *MasterStack) Resize(c Client, width, height int) {}
+
+func (ms *MasterStack) MakeMaster(c Client) {
+	for l := ms.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			ms.clients.MoveToFront(l)
+			ms.Place()
+			return
+		}
+	}
+}
+
+func (ms *MasterStack) FocusNext() {
+	cycleFocus(ms.clients, true)
+}
+
+func (ms *MasterStack) FocusPrev() {
+	cycleFocus(ms.clients, false)
+}
+
+func (ms *MasterStack) Replace(old, new Client) {
+	replaceInList(ms.clients, old, new)
+}
+
+func (ms *MasterStack) Swap(c1, c2 Client) {
+	if swapInList(ms.clients, c1, c2) {
+		ms.Place()
+	}
+}
@@ -0,0 +1,140 @@
+package layout
+
+import (
+	"container/list"
+
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+// Monocle is a layout where every client covers the whole workspace, minus
+// the tile padding. Only the active client is raised, so FocusNext and
+// FocusPrev are used to flip through the clients.
+type Monocle struct {
+	clients *list.List
+	geom    xrect.Rect
+
+	// added is the client added most recently, which is raised instead of
+	// the active client the next time the layout is placed.
+	added Client
+}
+
+func NewMonocle() *Monocle {
+	return &Monocle{
+		clients: list.New(),
+	}
+}
+
+func (m *Monocle) Name() string {
+	return "Monocle"
+}
+
+func (m *Monocle) SetGeom(geom xrect.Rect) {
+	m.geom = geom
+}
+
+func (m *Monocle) Place() {
+	if m.geom == nil {
+		return
+	}
+
+	gap := config.Settings.Gap
+	padding := config.Settings.TilePadding - (gap / 2)
+
+	x := m.geom.X() + padding
+	y := m.geom.Y() + padding
+	width := m.geom.Width() - (padding * 2)
+	height := m.geom.Height() - (padding * 2)
+
+	top := m.added
+	for l := m.clients.Front(); l != nil; l = l.Next() {
+		c := l.Value.(Client)
+		if top == nil && c.IsActive() {
+			top = c
+		}
+
+		c.FrameTile()
+		c.MoveResize(x+(gap/2), y+(gap/2), width-gap, height-gap)
+	}
+	if top != nil {
+		top.Raise()
+	}
+	m.added = nil
+}
+
+func (m *Monocle) Unplace() {}
+
+func (m *Monocle) Add(c Client) {
+	if !m.Exists(c) {
+		m.clients.PushBack(c)
+		m.added = c
+	}
+}
+
+func (m *Monocle) Remove(c Client) {
+	for l := m.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			m.clients.Remove(l)
+			if m.added == c {
+				m.added = nil
+			}
+			return
+		}
+	}
+}
+
+func (m *Monocle) Exists(c Client) bool {
+	for l := m.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Monocle) Destroy() {}
+
+func (m *Monocle) Save() {}
+
+func (m *Monocle) Reposition() {}
+
+func (m *Monocle) MROpt(c Client, flags, x, y, width, height int) {}
+
+func (m *Monocle) MoveResize(c Client, x, y, width, height int) {}
+
+func (m *Monocle) Move(c Client, x, y int) {}
+
+func (m *Monocle) Resize(c Client, width, height int) {}
+
+// MakeMaster moves the client to the front of the list and raises it.
+func (m *Monocle) MakeMaster(c Client) {
+	for l := m.clients.Back(); l != nil; l = l.Prev() {
+		if l.Value.(Client) == c {
+			m.clients.MoveToFront(l)
+			c.Raise()
+			return
+		}
+	}
+}
+
+func (m *Monocle) FocusNext() {
+	cycleFocus(m.clients, true)
+}
+
+func (m *Monocle) FocusPrev() {
+	cycleFocus(m.clients, false)
+}
+
+func (m *Monocle) Replace(old, new Client) {
+	replaceInList(m.clients, old, new)
+	if m.added == old {
+		m.added = new
+	}
+}
+
+func (m *Monocle) Swap(c1, c2 Client) {
+	if swapInList(m.clients, c1, c2) {
+		m.Place()
+	}
+}
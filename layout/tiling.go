@@ -28,8 +28,8 @@ func (t *Tiling) SetGeom(geom xrect.Rect) {
 }
 
 func (t *Tiling) Place() {
-	gap := config.SettingsVal["gap"].(int)
-	padding := config.SettingsVal["tilepadding"].(int) - (gap / 2)
+	gap := config.Settings.Gap
+	padding := config.Settings.TilePadding - (gap / 2)
 
 	x := t.geom.X() + padding
 	y := t.geom.Y() + padding
@@ -127,3 +127,21 @@ func (t *Tiling) MakeMaster(c Client) {
 		}
 	}
 }
+
+func (t *Tiling) FocusNext() {
+	cycleFocus(t.clients, true)
+}
+
+func (t *Tiling) FocusPrev() {
+	cycleFocus(t.clients, false)
+}
+
+func (t *Tiling) Replace(old, new Client) {
+	replaceInList(t.clients, old, new)
+}
+
+func (t *Tiling) Swap(c1, c2 Client) {
+	if swapInList(t.clients, c1, c2) {
+		t.Place()
+	}
+}
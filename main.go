@@ -18,6 +18,7 @@ import (
 
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/keybind"
 	"github.com/BurntSushi/xgbutil/mousebind"
 	"github.com/BurntSushi/xgbutil/xevent"
 
@@ -58,9 +59,8 @@ func init() {
 			"is running, SponeWM will exit.")
 	flag.StringVar(&flagConfigDir, "config-dir", flagConfigDir,
 		"Override the location of the configuration files. When this\n"+
-			"is not set, the following paths (roughly) will be checked\n"+
-			"in order: $XDG_CONFIG_DIR/sponewm, /etc/xdg/sponewm,\n"+
-			"$GOPATH/src/github.com/onodera-punpun/sponewm/config")
+			"is not set, $XDG_CONFIG_HOME/sponewm is used, or\n"+
+			"$HOME/.config/sponewm if XDG_CONFIG_HOME is not set.")
 	flag.BoolVar(&flagSponeRestarted, "spone-restarted", flagSponeRestarted,
 		"DO NOT USE. INTERNAL SPONE USE ONLY.")
 
@@ -98,7 +98,10 @@ func main() {
 	}
 
 	// TODO: Move theme here
+	config.SetConfigDir(flagConfigDir)
+	config.CheckMatch = commands.CheckMatch
 	config.Initialize()
+	keybind.Initialize(X)
 	mousebind.Initialize(X)
 	focus.Initialize(X)
 	stack.Initialize(X)
@@ -133,6 +136,11 @@ func main() {
 
 	wm.FocusFallback()
 	wm.Startup = false
+
+	// The autostart commands only run once per X session.
+	if !flagSponeRestarted {
+		wm.Autostart()
+	}
 	pingBefore, pingAfter, pingQuit := xevent.MainPing(X)
 
 	if len(flagCpuProfile) > 0 {
@@ -152,6 +160,8 @@ EVENTLOOP:
 			<-pingAfter
 		case f := <-commands.SafeExec:
 			commands.SafeReturn <- f()
+		case f := <-wm.Deferred:
+			f()
 		case <-pingQuit:
 			break EVENTLOOP
 		}
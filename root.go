@@ -4,11 +4,13 @@ import (
 	"github.com/BurntSushi/xgb/xproto"
 
 	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/xevent"
 	"github.com/BurntSushi/xgbutil/xprop"
 	"github.com/BurntSushi/xgbutil/xrect"
 	"github.com/BurntSushi/xgbutil/xwindow"
 
+	"github.com/onodera-punpun/sponewm/config"
 	"github.com/onodera-punpun/sponewm/focus"
 	"github.com/onodera-punpun/sponewm/logger"
 	"github.com/onodera-punpun/sponewm/wm"
@@ -23,6 +25,7 @@ func rootInit(X *xgbutil.XUtil) {
 		xproto.EventMaskFocusChange |
 		xproto.EventMaskButtonPress |
 		xproto.EventMaskButtonRelease |
+		xproto.EventMaskEnterWindow |
 		xproto.EventMaskStructureNotify |
 		xproto.EventMaskSubstructureNotify |
 		xproto.EventMaskSubstructureRedirect
@@ -65,6 +68,24 @@ func rootInit(X *xgbutil.XUtil) {
 			wm.FocusFallback()
 		}).Connect(X, wm.Root.Id)
 
+	// The pointer moved from a window onto the desktop. Any focus change
+	// that is still waiting for the pointer to rest in a window is dropped,
+	// and the strict focus model removes the focus.
+	xevent.EnterNotifyFun(
+		func(X *xgbutil.XUtil, ev xevent.EnterNotifyEvent) {
+			if ev.Mode != xproto.NotifyModeNormal {
+				return
+			}
+			wm.Hover(func() {
+				if config.Settings.FocusModel == "strict" {
+					focus.Root()
+				}
+			})
+		}).Connect(X, wm.Root.Id)
+
+	// Pagers may rename workspaces by writing _NET_DESKTOP_NAMES.
+	xevent.PropertyNotifyFun(handleRootProperty).Connect(X, wm.Root.Id)
+
 	// Listen to Root client message events. This is how we handle all
 	// of the EWMH bullshit.
 	xevent.ClientMessageFun(handleClientMessages).Connect(X, wm.Root.Id)
@@ -78,10 +99,13 @@ func handleClientMessages(X *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
 	}
 	switch name {
 	case "_NET_NUMBER_OF_DESKTOPS":
-		logger.Warning.Printf("SponeWM does not support adding/removing " +
-			"desktops using the _NET_NUMBER_OF_DESKTOPS property. Please use " +
-			"the Spone commands 'AddWorkspace' and 'RemoveWorkspace' to add " +
-			"or remove workspaces.")
+		// Workspaces are added or removed at the end. If a workspace can't be
+		// removed, _NET_NUMBER_OF_DESKTOPS keeps the number there is.
+		n := int(ev.Data.Data32[0])
+		if err := wm.SetNumWorkspaces(n); err != nil {
+			logger.Warning.Printf("Could not change the number of "+
+				"workspaces to %d: %s", n, err)
+		}
 	case "_NET_DESKTOP_GEOMETRY":
 		logger.Warning.Printf("SponeWM does not support the " +
 			"_NET_DESKTOP_GEOMETRY property. Namely, more than one workspace " +
@@ -104,6 +128,28 @@ func handleClientMessages(X *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
 	}
 }
 
+func handleRootProperty(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
+	name, err := xprop.AtomName(X, ev.Atom)
+	if err != nil || name != "_NET_DESKTOP_NAMES" {
+		return
+	}
+	if ev.State == xproto.PropertyDelete {
+		return
+	}
+
+	// SponeWM sets the property itself whenever a workspace is renamed, which
+	// leads here too. Those names are the same as the current ones.
+	names, err := ewmh.DesktopNamesGet(X)
+	if err != nil {
+		logger.Warning.Printf("Could not get _NET_DESKTOP_NAMES: %s", err)
+		return
+	}
+	if err := wm.SetWorkspaceNames(names); err != nil {
+		logger.Warning.Printf("Could not rename every workspace as asked "+
+			"by _NET_DESKTOP_NAMES: %s", err)
+	}
+}
+
 func handleMotionNotify(X *xgbutil.XUtil, ev xevent.MotionNotifyEvent) {
 	qp, err := xproto.QueryPointer(X.Conn(), X.RootWin()).Reply()
 	if err != nil {
@@ -17,9 +17,12 @@ import (
 	"github.com/BurntSushi/cmd"
 
 	"github.com/onodera-punpun/sponewm/commands"
+	"github.com/onodera-punpun/sponewm/config"
 )
 
 var (
+	flagConfigDir         = ""
+	flagDumpDefaultConfig = false
 	flagFileInput         = ""
 	flagListCommands      = false
 	flagListTypeCommands  = false
@@ -44,6 +47,13 @@ func init() {
 	flag.IntVar(&flagPoll, "poll", flagPoll,
 		"When greater than 0, the commands specified will be repeated at "+
 			"the interval specified in milliseconds.")
+	flag.BoolVar(&flagDumpDefaultConfig, "dump-default-config",
+		flagDumpDefaultConfig,
+		"Write the default settings.json and bindings.json, annotated with\n"+
+			"comments, to the configuration directory. Existing files are\n"+
+			"never overwritten.")
+	flag.StringVar(&flagConfigDir, "config-dir", flagConfigDir,
+		"Override the configuration directory used by --dump-default-config.")
 
 	flag.Usage = usage
 	flag.Parse()
@@ -171,6 +181,14 @@ func handleFlags() {
 		help := commands.Env.Help(flagUsageCommand)
 		fmt.Printf("\t%s\n", strings.Replace(help, "\n", "\n\t", -1))
 		os.Exit(0)
+	case flagDumpDefaultConfig:
+		config.SetConfigDir(flagConfigDir)
+		if err := config.DumpDefaults(config.ConfigDir()); err != nil {
+			log.Fatalf("Could not write the default configuration: %s", err)
+		}
+		fmt.Printf("Wrote the default configuration to %s\n",
+			config.ConfigDir())
+		os.Exit(0)
 	}
 }
 
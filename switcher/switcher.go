@@ -0,0 +1,274 @@
+// Package switcher draws the window switcher that is shown while cycling
+// through clients with CycleClientNext and CycleClientPrev. It lists the
+// icon and title of every client and highlights the selected one. Grabbing
+// the keyboard and acting on the selection is up to the caller.
+package switcher
+
+import (
+	"image"
+	"image/color"
+	"os"
+
+	"github.com/BurntSushi/freetype-go/freetype/truetype"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/xgraphics"
+	"github.com/BurntSushi/xgbutil/xrect"
+	"github.com/BurntSushi/xgbutil/xwindow"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+const (
+	iconSize = 24
+	padding  = 8
+	minWidth = 300
+)
+
+var (
+	bgColor       = xgraphics.BGRA{B: 0x22, G: 0x22, R: 0x22, A: 0xff}
+	selectedColor = xgraphics.BGRA{B: 0x55, G: 0x55, R: 0x55, A: 0xff}
+	textColor     = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+)
+
+// Item is a client listed in the switcher.
+type Item interface {
+	Id() xproto.Window
+	Name() string
+}
+
+// Switcher is an override redirect window that lists clients. It is created
+// once and shown again for every cycle.
+type Switcher struct {
+	X   *xgbutil.XUtil
+	win *xwindow.Window
+	img *xgraphics.Image
+
+	geom     xrect.Rect
+	items    []Item
+	icons    []*xgraphics.Image
+	selected int
+	showing  bool
+
+	// font is parsed from fontFile, which is the 'font' setting it was
+	// loaded for. font is nil if that file couldn't be loaded.
+	font     *truetype.Font
+	fontFile string
+}
+
+// New creates the window of the switcher. The window receives key events,
+// so it can be used to grab the keyboard while the switcher is shown.
+func New(X *xgbutil.XUtil) *Switcher {
+	win, err := xwindow.Generate(X)
+	if err != nil {
+		logger.Error.Fatalf("Could not create the switcher window: %s", err)
+	}
+	win.Create(X.RootWin(), 0, 0, 1, 1,
+		xproto.CwOverrideRedirect|xproto.CwEventMask,
+		1, xproto.EventMaskKeyPress|xproto.EventMaskKeyRelease)
+
+	// Pagers and taskbars shouldn't list the switcher.
+	ewmh.WmWindowTypeSet(X, win.Id, []string{"_NET_WM_WINDOW_TYPE_DIALOG"})
+	ewmh.WmStateSet(X, win.Id, []string{
+		"_NET_WM_STATE_SKIP_TASKBAR", "_NET_WM_STATE_SKIP_PAGER",
+	})
+
+	return &Switcher{
+		X:   X,
+		win: win,
+	}
+}
+
+// Id returns the id of the switcher window.
+func (sw *Switcher) Id() xproto.Window {
+	return sw.win.Id
+}
+
+// Showing returns true if the switcher is shown.
+func (sw *Switcher) Showing() bool {
+	return sw.showing
+}
+
+// Show lists items, which must not be empty, in the center of geom and
+// selects the item at index selected.
+func (sw *Switcher) Show(geom xrect.Rect, items []Item, selected int) {
+	sw.geom = geom
+	sw.items = items
+	sw.selected = selected
+
+	sw.icons = make([]*xgraphics.Image, len(items))
+	for i, item := range items {
+		icon, err := xgraphics.FindIcon(sw.X, item.Id(), iconSize, iconSize)
+		if err == nil {
+			sw.icons[i] = icon
+		}
+	}
+
+	sw.draw()
+	sw.win.Map()
+	sw.win.Stack(xproto.StackModeAbove)
+	sw.showing = true
+}
+
+// Hide unmaps the switcher and forgets its items.
+func (sw *Switcher) Hide() {
+	if !sw.showing {
+		return
+	}
+	sw.win.Unmap()
+	sw.showing = false
+
+	for _, icon := range sw.icons {
+		if icon != nil {
+			icon.Destroy()
+		}
+	}
+	sw.items, sw.icons = nil, nil
+	if sw.img != nil {
+		sw.img.Destroy()
+		sw.img = nil
+	}
+}
+
+// Selected returns the selected item, or nil if the switcher isn't shown.
+func (sw *Switcher) Selected() Item {
+	if !sw.showing {
+		return nil
+	}
+	return sw.items[sw.selected]
+}
+
+// Next selects the item after the selected one, wrapping around at the end.
+func (sw *Switcher) Next() {
+	if !sw.showing {
+		return
+	}
+	sw.selected = (sw.selected + 1) % len(sw.items)
+	sw.draw()
+}
+
+// Prev selects the item before the selected one, wrapping around at the
+// start.
+func (sw *Switcher) Prev() {
+	if !sw.showing {
+		return
+	}
+	sw.selected = (sw.selected - 1 + len(sw.items)) % len(sw.items)
+	sw.draw()
+}
+
+// draw renders the items into a new image, which becomes the background of
+// the switcher window, and centers the window in geom.
+func (sw *Switcher) draw() {
+	font := sw.loadFont()
+	fontSize := float64(config.Settings.FontSize)
+	rowHeight := iconSize + padding
+
+	// Only as many rows as fit on the head are drawn, starting with the row
+	// that keeps the selected item visible.
+	rows := (sw.geom.Height() - padding) / rowHeight
+	if rows < 1 {
+		rows = 1
+	}
+	if rows > len(sw.items) {
+		rows = len(sw.items)
+	}
+	first := 0
+	if sw.selected >= rows {
+		first = sw.selected - rows + 1
+	}
+
+	textWidth := 0
+	if font != nil {
+		for _, item := range sw.items[first : first+rows] {
+			if w, _ := xgraphics.Extents(font, fontSize, item.Name()); w >
+				textWidth {
+
+				textWidth = w
+			}
+		}
+	}
+	width := textWidth + iconSize + padding*3
+	if width < minWidth {
+		width = minWidth
+	}
+	if width > sw.geom.Width() {
+		width = sw.geom.Width()
+	}
+	height := rows*rowHeight + padding
+
+	img := xgraphics.New(sw.X, image.Rect(0, 0, width, height))
+	img.For(func(x, y int) xgraphics.BGRA {
+		return bgColor
+	})
+	for i := first; i < first+rows; i++ {
+		y := padding/2 + (i-first)*rowHeight
+		row := image.Rect(padding/2, y, width-padding/2, y+rowHeight)
+		if i == sw.selected {
+			sub := img.SubImage(row).(*xgraphics.Image)
+			sub.For(func(x, y int) xgraphics.BGRA {
+				return selectedColor
+			})
+		}
+
+		if icon := sw.icons[i]; icon != nil {
+			r := image.Rect(padding, y+padding/2,
+				padding+iconSize, y+padding/2+iconSize)
+			sub := img.SubImage(r).(*xgraphics.Image)
+			xgraphics.Blend(sub, icon, icon.Bounds().Min)
+		}
+		if font != nil {
+			// The text is clipped to the row, minus the padding.
+			r := image.Rect(iconSize+padding*2, y,
+				width-padding, y+rowHeight)
+			sub := img.SubImage(r).(*xgraphics.Image)
+			_, textHeight := xgraphics.Extents(font, fontSize,
+				sw.items[i].Name())
+			sub.Text(r.Min.X, y+(rowHeight-textHeight)/2, textColor,
+				fontSize, font, sw.items[i].Name())
+		}
+	}
+
+	if err := img.XSurfaceSet(sw.win.Id); err != nil {
+		logger.Warning.Printf("Could not draw the switcher: %s", err)
+		img.Destroy()
+		return
+	}
+	img.XDraw()
+	sw.win.MoveResize(sw.geom.X()+(sw.geom.Width()-width)/2,
+		sw.geom.Y()+(sw.geom.Height()-height)/2, width, height)
+	img.XPaint(sw.win.Id)
+
+	if sw.img != nil {
+		sw.img.Destroy()
+	}
+	sw.img = img
+}
+
+// loadFont returns the font in the 'font' setting, which is loaded again
+// only when the setting changes. If the font can't be loaded, a warning is
+// logged once and nil is returned, in which case titles aren't drawn.
+func (sw *Switcher) loadFont() *truetype.Font {
+	if sw.fontFile == config.Settings.Font {
+		return sw.font
+	}
+	sw.fontFile = config.Settings.Font
+	sw.font = nil
+
+	f, err := os.Open(sw.fontFile)
+	if err != nil {
+		logger.Warning.Printf("Could not load the switcher font: %s", err)
+		return nil
+	}
+	defer f.Close()
+
+	if sw.font, err = xgraphics.ParseFont(f); err != nil {
+		logger.Warning.Printf("Could not load the switcher font '%s': %s",
+			sw.fontFile, err)
+	}
+	return sw.font
+}
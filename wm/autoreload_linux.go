@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+package wm
+
+import (
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// autoReloadDelay is how long to wait for the configuration directory to
+// settle down before reloading. Editors tend to save a file in several steps.
+const autoReloadDelay = 500 * time.Millisecond
+
+// autoReloadFile is the inotify instance watching the configuration
+// directory, or nil when automatic reloading is off.
+var autoReloadFile *os.File
+
+// autoReloadSet starts or stops watching the configuration directory and its
+// "images" directory for changes. Whenever something changes, the
+// ReloadConfig command is run.
+func autoReloadSet(on bool) {
+	if on == (autoReloadFile != nil) {
+		return
+	}
+	if !on {
+		autoReloadFile.Close()
+		autoReloadFile = nil
+		return
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		logger.Warning.Printf("Could not watch the configuration directory: "+
+			"%s", err)
+		return
+	}
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO)
+	dir := config.ConfigDir()
+	for _, dir := range []string{dir, path.Join(dir, "images")} {
+		if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+			logger.Warning.Printf("Could not watch '%s': %s", dir, err)
+		}
+	}
+
+	// Since the descriptor is non-blocking, closing the file interrupts the
+	// pending read in watchConfig.
+	autoReloadFile = os.NewFile(uintptr(fd), "inotify")
+	go watchConfig(autoReloadFile)
+}
+
+// watchConfig runs ReloadConfig after changes reported by inotify until f is
+// closed.
+func watchConfig(f *os.File) {
+	var timer *time.Timer
+	buf := make([]byte, 4096)
+	for {
+		if _, err := f.Read(buf); err != nil {
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(autoReloadDelay, func() {
+			// Errors are reported as the return value of the command.
+			val, err := gribbleEnv.Run("ReloadConfig")
+			if err != nil {
+				logger.Warning.Println(err)
+			} else if msg, ok := val.(string); ok && len(msg) > 0 {
+				logger.Warning.Println(msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package wm
+
+import (
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// autoReloadSet is a no-op, since inotify is only available on Linux.
+func autoReloadSet(on bool) {
+	if on {
+		logger.Warning.Printf("The 'autoreload' setting is only supported " +
+			"on Linux.")
+	}
+}
@@ -0,0 +1,25 @@
+package wm
+
+import (
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// Autostart runs the commands of the "autostart" setting in order. Commands
+// are executed by the main event loop, so they are run from a goroutine that
+// waits for it.
+func Autostart() {
+	cmds := config.Settings.Autostart
+	go func() {
+		for _, cmdStr := range cmds {
+			// Errors are reported as the return value of the command.
+			val, err := gribbleEnv.Run(cmdStr)
+			if err != nil {
+				logger.Warning.Printf("Could not run autostart command "+
+					"'%s': %s", cmdStr, err)
+			} else if msg, ok := val.(string); ok && len(msg) > 0 {
+				logger.Warning.Println(msg)
+			}
+		}
+	}()
+}
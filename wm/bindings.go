@@ -8,12 +8,13 @@ import (
 
 	"github.com/BurntSushi/xgbutil"
 	"github.com/BurntSushi/xgbutil/ewmh"
+	"github.com/BurntSushi/xgbutil/keybind"
 	"github.com/BurntSushi/xgbutil/mousebind"
 	"github.com/BurntSushi/xgbutil/xevent"
 
+	"github.com/onodera-punpun/sponewm/config"
 	"github.com/onodera-punpun/sponewm/cursors"
 	"github.com/onodera-punpun/sponewm/logger"
-	"github.com/onodera-punpun/sponewm/config"
 )
 
 // MouseClientClicked is a terrible hack to inject state into commands.
@@ -25,6 +26,15 @@ var (
 	mouseClientLock    = &sync.Mutex{}
 )
 
+// mouseBindings and keyBindings are the parsed contents of bindings.json.
+// Mouse bindings are keyed by section ("root", "client", "frame", ...) while
+// key bindings always live in the "keys" section and are grabbed on the root
+// window. Key modes are read from the "modes" section; see keymode.go.
+var (
+	mouseBindings map[string][]mouseCommand
+	keyBindings   []keyCommand
+)
+
 type mouseCommand struct {
 	cmdStr    string
 	cmdName   string
@@ -32,6 +42,116 @@ type mouseCommand struct {
 	buttonStr string
 }
 
+type keyCommand struct {
+	cmdStr  string
+	cmdName string
+	down    bool // 'up' when false
+	keyStr  string
+}
+
+// loadBindings parses every section of the bindings configuration into mouse
+// commands, key commands and key modes. Bindings that aren't valid Gribble
+// commands are logged and skipped.
+func loadBindings() {
+	mouseBindings = make(map[string][]mouseCommand)
+	keyBindings = make([]keyCommand, 0)
+	keyModes = make(map[string]*keyMode)
+
+	for section, val := range config.BindingsVal {
+		if config.IsComment(section) {
+			continue
+		}
+		switch section {
+		case "keys":
+			keyBindings = parseKeyCommands(section, val)
+		case "modes":
+			loadKeyModes(val)
+		default:
+			eachBinding(section, val, func(bindStr, cmdStr, cmdName string) {
+				down, justStr := isDown(bindStr)
+				mouseBindings[section] = append(mouseBindings[section],
+					mouseCommand{
+						cmdStr:    cmdStr,
+						cmdName:   cmdName,
+						down:      down,
+						buttonStr: justStr,
+					})
+			})
+		}
+	}
+}
+
+// parseKeyCommands returns the key commands declared in the bindings object
+// val. The section name is only used for error messages.
+func parseKeyCommands(section string, val interface{}) []keyCommand {
+	kcmds := make([]keyCommand, 0)
+	eachBinding(section, val, func(bindStr, cmdStr, cmdName string) {
+		down, justStr := isDown(bindStr)
+		kcmds = append(kcmds, keyCommand{
+			cmdStr:  cmdStr,
+			cmdName: cmdName,
+			down:    down,
+			keyStr:  justStr,
+		})
+	})
+	return kcmds
+}
+
+// eachBinding calls f for every valid binding in the bindings object val.
+// Bindings whose commands aren't strings or can't be parsed by Gribble are
+// logged and skipped.
+func eachBinding(section string, val interface{},
+	f func(bindStr, cmdStr, cmdName string)) {
+
+	binds, ok := val.(map[string]interface{})
+	if !ok {
+		logger.Warning.Printf("Bindings section '%s' is not an object.",
+			section)
+		return
+	}
+	for bindStr, cmd := range binds {
+		if config.IsComment(bindStr) {
+			continue
+		}
+		cmdStr, ok := cmd.(string)
+		if !ok {
+			logger.Warning.Printf("The command bound to '%s' in section "+
+				"'%s' is not a string.", bindStr, section)
+			continue
+		}
+		cmdName, err := commandName(cmdStr)
+		if err != nil {
+			logger.Warning.Printf("Could not parse command '%s' bound to "+
+				"'%s': %s", cmdStr, bindStr, err)
+			continue
+		}
+		f(bindStr, cmdStr, cmdName)
+	}
+}
+
+// commandName validates cmdStr as a Gribble command and returns the name of
+// the command being invoked. The match expressions in it are validated too.
+func commandName(cmdStr string) (string, error) {
+	if err := cmdHacks.CheckCommand(cmdStr); err != nil {
+		return "", err
+	}
+	return strings.Fields(cmdStr)[0], nil
+}
+
+// isDown determines whether a mouse/key string is a "down" or "up" event.
+// An "up" event is specified by appending " up" to the binding string, e.g.,
+// "Mod4-1 up".
+func isDown(bindStr string) (bool, string) {
+	fields := strings.Fields(bindStr)
+	if len(fields) == 2 && strings.ToLower(fields[1]) == "up" {
+		return false, fields[0]
+	}
+	if len(fields) != 1 {
+		logger.Warning.Printf("Binding string '%s' is not valid.", bindStr)
+	}
+	return true, bindStr
+}
+
 func (mcmd mouseCommand) setup(c Client, wid xproto.Window) {
 	// Check if this command is a drag... If it is, it needs special attention.
 	if mcmd.cmdName == "MouseMove" {
@@ -169,35 +289,99 @@ func (mcmd mouseCommand) attachGrabRelease(wid xproto.Window, run func()) {
 }
 
 func rootMouseSetup() {
-	for _, mcmd := range config.BindingsVal["root"].(map[string]interface{}) {
+	for _, mcmd := range mouseBindings["root"] {
+		mcmd := mcmd
 		run := func() {
 			go func() {
-				_, err := gribbleEnv.Run(mcmd.(string))
+				_, err := gribbleEnv.Run(mcmd.cmdStr)
 				if err != nil {
 					logger.Warning.Println(err)
 				}
 			}()
 		}
-		mcmd.(mouseCommand).attach(Root.Id, run, false, false)
+		mcmd.attach(Root.Id, run, false, false)
 	}
 }
 
 func ClientMouseSetup(c Client) {
-	for _, mcmd := range config.BindingsVal["client"].(map[string]interface{}) {
-		mcmd.(mouseCommand).setup(c, c.Id())
+	for _, mcmd := range mouseBindings["client"] {
+		mcmd.setup(c, c.Id())
 	}
 }
 
 func FrameMouseSetup(c Client, frameId xproto.Window) {
-	for _, mcmd := range config.BindingsVal["frame"].(map[string]interface{}) {
-		mcmd.(mouseCommand).setup(c, frameId)
+	for _, mcmd := range mouseBindings["frame"] {
+		mcmd.setup(c, frameId)
 	}
 }
 
 func FramePieceMouseSetup(c Client, piece string, pieceid xproto.Window) {
-	for _, mcmd := range config.BindingsVal[piece].(map[string]interface{}) {
-		mcmd.(mouseCommand).setup(c, pieceid)
+	for _, mcmd := range mouseBindings[piece] {
+		mcmd.setup(c, pieceid)
+	}
+}
+
+// rootKeySetup grabs every key binding on the root window.
+//
+// There is no need to handle MappingNotify events here: the keybind package
+// ungrabs and regrabs every key string it knows about whenever the keyboard
+// mapping changes.
+func rootKeySetup() {
+	for _, kcmd := range keyBindings {
+		kcmd.attach(Root.Id)
+	}
+}
+
+// attach grabs the key binding on wid and runs the command whenever the key
+// is pressed (or released).
+func (kcmd keyCommand) attach(wid xproto.Window) {
+	var err error
+	if kcmd.down {
+		err = keybind.KeyPressFun(
+			func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+				kcmd.run()
+			}).Connect(X, wid, kcmd.keyStr, true)
+	} else {
+		err = keybind.KeyReleaseFun(
+			func(X *xgbutil.XUtil, ev xevent.KeyReleaseEvent) {
+				kcmd.run()
+			}).Connect(X, wid, kcmd.keyStr, true)
+	}
+	if err != nil {
+		logger.Warning.Printf("Could not bind '%s': %s", kcmd.keyStr, err)
+	}
+}
+
+// run executes the key command's Gribble command in its own goroutine.
+// The commands that drive the window switcher are the exception: they are
+// run right away, since they need to know the key they are bound to.
+func (kcmd keyCommand) run() {
+	if isCycleCommand(kcmd.cmdName) {
+		cmdHacks.CycleClientRunWithKeyStr(kcmd.keyStr, kcmd.cmdStr)
+		return
+	}
+	go func() {
+		_, err := gribbleEnv.Run(kcmd.cmdStr)
+		if err != nil {
+			logger.Warning.Println(err)
+		}
+	}()
+}
+
+// matches returns true if the key command is bound to the (modifiers,
+// keycode) tuple of a key event.
+func (kcmd keyCommand) matches(state uint16, detail xproto.Keycode) bool {
+	mods, kc := keybind.DeduceKeyInfo(state, detail)
+	bmods, kcs, err := keybind.ParseString(X, kcmd.keyStr)
+	if err != nil || bmods != mods {
+		return false
+	}
+	for _, bkc := range kcs {
+		if bkc == kc {
+			return true
+		}
 	}
+	return false
 }
 
 // strToDirection converts a string representation of a mouse direction
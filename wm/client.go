@@ -19,6 +19,7 @@ type Client interface {
 	ImminentDestruction() bool
 	IsMaximized() bool
 	Remaximize()
+	ReloadConfig()
 
 	DragMoveBegin(rx, ry, ex, ey int) bool
 	DragMoveStep(rx, ry, ex, ey int)
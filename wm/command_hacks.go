@@ -2,4 +2,16 @@ package wm
 
 type CommandHacks struct {
 	MouseResizeDirection func(cmdStr string) (string, error)
+	RuleMatch            func(cmdStr string, c Client) (bool, error)
+	CheckCommand         func(cmdStr string) error
+
+	CycleClientRunWithKeyStr func(keyStr, cmdStr string)
+}
+
+// RuleMatch returns true if the match expression cmdStr, e.g., the 'match'
+// command of a window rule, holds for c. It is evaluated directly, so it may
+// be called while the main event loop is busy managing a client. See
+// commands.MatchClient.
+func RuleMatch(cmdStr string, c Client) (bool, error) {
+	return cmdHacks.RuleMatch(cmdStr, c)
 }
@@ -50,6 +50,16 @@ func ewmhDesktopNames() {
 	event.Notify(event.ChangedWorkspaceNames{})
 }
 
+// ewmhClientDesktops sets _NET_WM_DESKTOP of every client again. It must be
+// called whenever the index of a workspace changes.
+func ewmhClientDesktops() {
+	for _, c := range Clients {
+		if wrk, ok := c.Workspace().(*workspace.Workspace); ok {
+			ewmh.WmDesktopSet(X, c.Id(), uint(workspaceIndex(wrk)))
+		}
+	}
+}
+
 func ewmhDesktopGeometry() {
 	rgeom := xwindow.RootGeometry(X)
 
@@ -0,0 +1,124 @@
+package wm
+
+import (
+	"github.com/onodera-punpun/sponewm/workspace"
+)
+
+// maxHistory is the number of workspaces that each history remembers in
+// either direction.
+const maxHistory = 50
+
+// wrkHistory holds the workspaces that were shown on a head before, like the
+// history of a web browser. The last workspace in back is the one shown most
+// recently, unless WorkspaceBack or WorkspaceForward was used.
+//
+// previous is the workspace that was shown on the head right before the
+// current one, however it was left. It is used by 'workspacebackandforth'.
+type wrkHistory struct {
+	back, forward []*workspace.Workspace
+	previous      *workspace.Workspace
+}
+
+var (
+	// histories maps the index of a head to its history.
+	histories = make(map[int]*wrkHistory)
+
+	// navigatingHead is the index of the head that WorkspaceBack or
+	// WorkspaceForward switches, whose history they update themselves. It is
+	// -1 otherwise.
+	navigatingHead = -1
+)
+
+func headHistory(head int) *wrkHistory {
+	if h, ok := histories[head]; ok {
+		return h
+	}
+	h := &wrkHistory{}
+	histories[head] = h
+	return h
+}
+
+// visibleSnapshot returns a copy of the workspaces that are visible on each
+// head, which is passed to recordHistory once they may have changed.
+func visibleSnapshot() []*workspace.Workspace {
+	visibles := Heads.VisibleWorkspaces()
+	snapshot := make([]*workspace.Workspace, len(visibles))
+	copy(snapshot, visibles)
+	return snapshot
+}
+
+// recordHistory adds the workspace that was shown on a head to the history of
+// that head, if the head shows another workspace now. Going somewhere new
+// forgets the forward history, like in a web browser.
+func recordHistory(before []*workspace.Workspace) {
+	after := Heads.VisibleWorkspaces()
+	for i := 0; i < len(before) && i < len(after); i++ {
+		if before[i] == after[i] {
+			continue
+		}
+		h := headHistory(i)
+		h.previous = before[i]
+		if i == navigatingHead {
+			continue
+		}
+		h.back = pushHistory(h.back, before[i])
+		h.forward = nil
+	}
+}
+
+// pushHistory appends wrk to a list of workspaces, dropping the oldest one
+// if the list is full.
+func pushHistory(list []*workspace.Workspace,
+	wrk *workspace.Workspace) []*workspace.Workspace {
+
+	list = append(list, wrk)
+	if len(list) > maxHistory {
+		list = list[1:]
+	}
+	return list
+}
+
+// WorkspaceBack shows the workspace that was shown on the active head before
+// the active workspace. Workspaces that were removed since are skipped.
+func WorkspaceBack() {
+	navigateHistory(true)
+}
+
+// WorkspaceForward undoes WorkspaceBack.
+func WorkspaceForward() {
+	navigateHistory(false)
+}
+
+// WorkspaceBackAndForth shows the workspace that was shown on the active head
+// right before the active workspace. Using it twice toggles between the two.
+func WorkspaceBackAndForth() {
+	h := headHistory(Heads.VisibleIndex(Workspace()))
+	wrk := h.previous
+	if wrk == nil || wrk == Workspace() || Heads.GlobalIndex(wrk) == -1 {
+		return
+	}
+	SetWorkspace(wrk, true)
+}
+
+func navigateHistory(back bool) {
+	head := Heads.VisibleIndex(Workspace())
+	h := headHistory(head)
+	from, to := &h.back, &h.forward
+	if !back {
+		from, to = to, from
+	}
+
+	for len(*from) > 0 {
+		wrk := (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+		if wrk == Workspace() || Heads.GlobalIndex(wrk) == -1 {
+			continue
+		}
+
+		*to = pushHistory(*to, Workspace())
+		navigatingHead = head
+		SetWorkspace(wrk, true)
+		navigatingHead = -1
+		return
+	}
+}
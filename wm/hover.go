@@ -0,0 +1,41 @@
+package wm
+
+import (
+	"time"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+var (
+	// hoverGen is incremented whenever the pointer enters another window, so
+	// that a pending hover action is dropped once the pointer moves on.
+	hoverGen   int
+	hoverTimer *time.Timer
+)
+
+// Hover runs f once the pointer has rested in the window it just entered for
+// the 'focusdelay', which avoids focusing every window that the pointer
+// crosses. f is run from within the main event loop, and only if Hover isn't
+// called again in the meantime. Without a delay, f is run right away.
+func Hover(f func()) {
+	hoverGen++
+	if hoverTimer != nil {
+		hoverTimer.Stop()
+		hoverTimer = nil
+	}
+
+	delay := time.Duration(config.Settings.FocusDelay) * time.Millisecond
+	if delay <= 0 {
+		f()
+		return
+	}
+
+	gen := hoverGen
+	hoverTimer = time.AfterFunc(delay, func() {
+		Deferred <- func() {
+			if gen == hoverGen {
+				f()
+			}
+		}
+	})
+}
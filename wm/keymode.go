@@ -0,0 +1,215 @@
+package wm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/keybind"
+	"github.com/BurntSushi/xgbutil/xevent"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/event"
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// KeyModeDefault is the name of the key map that is active when no key mode
+// has been entered, i.e., the "keys" section of bindings.json.
+const KeyModeDefault = "default"
+
+// defaultKeyModeTimeout is used for modes that don't specify a timeout.
+const defaultKeyModeTimeout = 3 * time.Second
+
+// keyMode is a named key map declared in the "modes" section of
+// bindings.json. While a mode is active, the keyboard is grabbed and every
+// key press is looked up in the mode's key map instead of the default one.
+//
+// A mode is left when Escape is pressed or when no bound key has been
+// pressed for 'timeout'. A one shot mode is also left after the first key
+// press, which makes it suitable for key chords like "Mod4-w h".
+type keyMode struct {
+	name    string
+	oneshot bool
+	timeout time.Duration
+	keys    []keyCommand
+}
+
+var (
+	keyModes   map[string]*keyMode
+	curKeyMode *keyMode
+
+	// keyModeGen is incremented every time the key mode timer is reset, so
+	// that a timer that fires late doesn't leave a mode entered since.
+	keyModeGen   int
+	keyModeTimer *time.Timer
+)
+
+// loadKeyModes parses the "modes" section of bindings.json. Each mode is an
+// object with a "keys" object of bindings and the optional "oneshot" (bool)
+// and "timeout" (milliseconds, 0 disables it) options.
+func loadKeyModes(val interface{}) {
+	modes, ok := val.(map[string]interface{})
+	if !ok {
+		logger.Warning.Printf("Bindings section 'modes' is not an object.")
+		return
+	}
+	for name, modeVal := range modes {
+		if config.IsComment(name) {
+			continue
+		}
+		if name == KeyModeDefault {
+			logger.Warning.Printf("The key mode name '%s' is reserved.", name)
+			continue
+		}
+		opts, ok := modeVal.(map[string]interface{})
+		if !ok {
+			logger.Warning.Printf("Key mode '%s' is not an object.", name)
+			continue
+		}
+
+		mode := &keyMode{
+			name:    name,
+			timeout: defaultKeyModeTimeout,
+			keys:    parseKeyCommands(name, opts["keys"]),
+		}
+		if v, ok := opts["oneshot"]; ok {
+			if mode.oneshot, ok = v.(bool); !ok {
+				logger.Warning.Printf("The 'oneshot' option of key mode "+
+					"'%s' is not a boolean.", name)
+			}
+		}
+		if v, ok := opts["timeout"]; ok {
+			// Numbers read from JSON are float64, while the defaults are int.
+			switch ms := v.(type) {
+			case float64:
+				mode.timeout = time.Duration(ms) * time.Millisecond
+			case int:
+				mode.timeout = time.Duration(ms) * time.Millisecond
+			default:
+				logger.Warning.Printf("The 'timeout' option of key mode "+
+					"'%s' is not a number.", name)
+			}
+		}
+		keyModes[name] = mode
+	}
+}
+
+// keyModeSetup attaches the handlers that receive key events while a key
+// mode is active. Key events are redirected to the dummy window when a mode
+// is entered, so the handlers are only attached once.
+func keyModeSetup() {
+	xevent.KeyPressFun(
+		func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+			keyModeHandle(ev.State, ev.Detail, true)
+		}).Connect(X, X.Dummy())
+	xevent.KeyReleaseFun(
+		func(X *xgbutil.XUtil, ev xevent.KeyReleaseEvent) {
+			keyModeHandle(ev.State, ev.Detail, false)
+		}).Connect(X, X.Dummy())
+}
+
+// keyModeHandle runs the command bound to a key in the active key mode.
+func keyModeHandle(state uint16, detail xproto.Keycode, down bool) {
+	mode := curKeyMode
+	if mode == nil {
+		return
+	}
+
+	// Pressing or releasing a modifier on its own never leaves a mode.
+	if keybind.ModGet(X, detail) != 0 {
+		return
+	}
+	if down && keybind.KeyMatch(X, "Escape", state, detail) {
+		KeyModeExit()
+		return
+	}
+	for _, kcmd := range mode.keys {
+		if kcmd.down == down && kcmd.matches(state, detail) {
+			kcmd.run()
+			if mode.oneshot {
+				KeyModeExit()
+			} else {
+				keyModeResetTimer()
+			}
+			return
+		}
+	}
+
+	// An unbound key cancels a key chord.
+	if down && mode.oneshot {
+		KeyModeExit()
+	}
+}
+
+// KeyMode returns the name of the active key mode.
+func KeyMode() string {
+	if curKeyMode == nil {
+		return KeyModeDefault
+	}
+	return curKeyMode.name
+}
+
+// KeyModeSet grabs the keyboard and makes the key mode named 'name' the
+// active key map. Setting the mode to KeyModeDefault is the same as calling
+// KeyModeExit.
+func KeyModeSet(name string) error {
+	if name == KeyModeDefault {
+		KeyModeExit()
+		return nil
+	}
+	mode, ok := keyModes[name]
+	if !ok {
+		return fmt.Errorf("No key mode named '%s' exists.", name)
+	}
+	if curKeyMode == nil {
+		if err := keybind.DummyGrab(X); err != nil {
+			return fmt.Errorf("Could not enter key mode '%s': %s", name, err)
+		}
+	}
+	curKeyMode = mode
+	keyModeResetTimer()
+
+	event.Notify(event.ChangedKeyMode{mode.name})
+	return nil
+}
+
+// KeyModeExit ungrabs the keyboard and returns to the default key map.
+// It does nothing if no key mode is active.
+func KeyModeExit() {
+	if curKeyMode == nil {
+		return
+	}
+	curKeyMode = nil
+	keyModeGen++
+	if keyModeTimer != nil {
+		keyModeTimer.Stop()
+		keyModeTimer = nil
+	}
+	keybind.DummyUngrab(X)
+
+	event.Notify(event.ChangedKeyMode{KeyModeDefault})
+}
+
+// keyModeResetTimer (re)starts the timeout of the active key mode. The mode
+// is left from within the main event loop via Deferred.
+func keyModeResetTimer() {
+	keyModeGen++
+	if keyModeTimer != nil {
+		keyModeTimer.Stop()
+		keyModeTimer = nil
+	}
+	if curKeyMode == nil || curKeyMode.timeout <= 0 {
+		return
+	}
+
+	gen := keyModeGen
+	keyModeTimer = time.AfterFunc(curKeyMode.timeout, func() {
+		Deferred <- func() {
+			if gen == keyModeGen {
+				KeyModeExit()
+			}
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package wm
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/xgbutil"
+
+	"github.com/BurntSushi/xgbutil/keybind"
+	"github.com/BurntSushi/xgbutil/mousebind"
+
+	"github.com/onodera-punpun/sponewm/config"
+)
+
+// ReloadConfig reads settings.json, bindings.json and the theme again and
+// applies them without restarting. If a configuration file can't be read or
+// parsed, nothing changes. If the theme can't be loaded, the rest of the
+// configuration is still applied and the old theme is kept.
+func ReloadConfig() error {
+	if err := config.Reload(); err != nil {
+		return err
+	}
+	theme, themeErr := newTheme()
+	if themeErr == nil {
+		Theme = theme
+	}
+
+	// Throw away every root binding before grabbing the new ones.
+	KeyModeExit()
+	if SwitcherShowing() {
+		switcherFinish(false)
+	}
+	keybind.Detach(X, Root.Id)
+	forgetRootKeys()
+	mousebind.Detach(X, Root.Id)
+	loadBindings()
+	rootMouseSetup()
+	rootKeySetup()
+
+	for _, c := range Clients {
+		c.ReloadConfig()
+	}
+
+	// Gaps and padding are only used when placing clients.
+	for _, wrk := range Heads.Workspaces.Wrks {
+		wrk.Place()
+	}
+	autoReloadSet(config.Settings.AutoReload)
+
+	if themeErr != nil {
+		return fmt.Errorf("Could not reload the theme: %s", themeErr)
+	}
+	return nil
+}
+
+// forgetRootKeys drops the key strings of the root window from X.Keystrings.
+// keybind.Detach removes their callbacks but keeps the key strings, which
+// xgbutil grabs again on every keyboard mapping change. Without this, removed
+// bindings would come back and the others would run more than once.
+func forgetRootKeys() {
+	X.KeybindsLck.Lock()
+	defer X.KeybindsLck.Unlock()
+
+	kept := make([]xgbutil.KeyString, 0, len(X.Keystrings))
+	for _, ks := range X.Keystrings {
+		if ks.Win != Root.Id {
+			kept = append(kept, ks)
+		}
+	}
+	X.Keystrings = kept
+}
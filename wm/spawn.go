@@ -0,0 +1,122 @@
+package wm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/onodera-punpun/sponewm/logger"
+)
+
+// spawnTimeout is how long the windows of a program started with a target
+// workspace are sent to that workspace.
+const spawnTimeout = 30 * time.Second
+
+// spawned is a program started by Spawn with a target workspace.
+type spawned struct {
+	pid       int
+	startupID string
+	workspace string
+}
+
+var (
+	spawns     []*spawned
+	spawnCount int
+)
+
+// Spawn runs cmdStr with the shell in its own session, so that it outlives
+// SponeWM. If workspace isn't empty, windows opened by the program in the
+// next 30 seconds are put on that workspace. Windows are recognized by
+// their _NET_WM_PID, which must be the started process or one of its
+// descendants, or by the startup notification id passed to the program in
+// DESKTOP_STARTUP_ID.
+func Spawn(cmdStr, workspace string) error {
+	spawnCount++
+	startupID := fmt.Sprintf("sponewm-%d-%d_TIME%d",
+		os.Getpid(), spawnCount, X.TimeGet())
+
+	cmd := exec.Command("sh", "-c", cmdStr)
+	cmd.Env = append(os.Environ(), "DESKTOP_STARTUP_ID="+startupID)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Could not run '%s': %s", cmdStr, err)
+	}
+
+	// Reap the process when it exits.
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			logger.Message.Printf("'%s' exited: %s", cmdStr, err)
+		}
+	}()
+
+	if len(workspace) == 0 {
+		return nil
+	}
+	s := &spawned{cmd.Process.Pid, startupID, workspace}
+	spawns = append(spawns, s)
+	time.AfterFunc(spawnTimeout, func() {
+		Deferred <- func() {
+			for i, s2 := range spawns {
+				if s2 == s {
+					spawns = append(spawns[:i], spawns[i+1:]...)
+					break
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// SpawnedWorkspace returns the name of the workspace that a new window should
+// be put on because it belongs to a program started by Spawn. pid is the
+// _NET_WM_PID and startupID the _NET_STARTUP_ID of the window, either of
+// which may be missing (zero or empty). An empty string is returned if the
+// window doesn't belong to such a program.
+func SpawnedWorkspace(pid int, startupID string) string {
+	for _, s := range spawns {
+		if len(startupID) > 0 && startupID == s.startupID {
+			return s.workspace
+		}
+	}
+	if pid <= 0 {
+		return ""
+	}
+	for _, s := range spawns {
+		if IsDescendant(pid, s.pid) {
+			return s.workspace
+		}
+	}
+	return ""
+}
+
+// IsDescendant returns true if the process pid is the process ancestor or
+// one of its descendants. Parent processes are looked up in /proc, so this
+// always returns false for distinct processes on systems without it.
+func IsDescendant(pid, ancestor int) bool {
+	for pid > 1 {
+		if pid == ancestor {
+			return true
+		}
+		stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return false
+		}
+
+		// The parent pid is the second field after the command name, which
+		// is in parentheses and may contain spaces.
+		str := string(stat)
+		fields := strings.Fields(str[strings.LastIndex(str, ")")+1:])
+		if len(fields) < 2 {
+			return false
+		}
+		if pid, err = strconv.Atoi(fields[1]); err != nil {
+			return false
+		}
+	}
+	return false
+}
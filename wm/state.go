@@ -2,6 +2,7 @@ package wm
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/BurntSushi/gribble"
 
@@ -35,6 +36,11 @@ var (
 	Restart    bool
 )
 
+// Deferred is a channel of functions that are executed synchronously with
+// respect to the main X event loop. It is useful for running state changes
+// from timers or other goroutines.
+var Deferred = make(chan func(), 1)
+
 func Initialize(x *xgbutil.XUtil,
 	cmdEnv *gribble.Environment, hacks CommandHacks) {
 
@@ -53,7 +59,7 @@ func Initialize(x *xgbutil.XUtil,
 
 	Clients = make(ClientList, 0, 50)
 
-	Heads = heads.NewHeads(X, config.SettingsVal["defaultlayout"].(string))
+	Heads = heads.NewHeads(X, config.Settings.DefaultLayout)
 
 	// If _NET_DESKTOP_NAMES is set, let's use workspaces from that instead.
 	if names, _ := ewmh.DesktopNamesGet(X); len(names) > 0 {
@@ -64,8 +70,8 @@ func Initialize(x *xgbutil.XUtil,
 			}
 		}
 	} else {
-		for _, wrkName := range config.SettingsVal["workspaces"].([]interface{}) {
-			if err := AddWorkspace(wrkName.(string)); err != nil {
+		for _, wrkName := range config.Settings.Workspaces {
+			if err := AddWorkspace(wrkName); err != nil {
 				logger.Error.Fatalf("Could not initialize workspaces: %s", err)
 			}
 		}
@@ -85,6 +91,14 @@ func Initialize(x *xgbutil.XUtil,
 
 	Restart = false
 
+	Theme = loadTheme()
+	loadBindings()
+	rootMouseSetup()
+	rootKeySetup()
+	keyModeSetup()
+	switcherSetup()
+	autoReloadSet(config.Settings.AutoReload)
+
 	ewmhClientList()
 	ewmhNumberOfDesktops()
 	ewmhCurrentDesktop()
@@ -143,17 +157,35 @@ func Workspace() *workspace.Workspace {
 }
 
 func SetWorkspace(wrk *workspace.Workspace, greedy bool) {
+	before := visibleSnapshot()
 	wrk.Activate(greedy)
 
 	ewmhVisibleDesktops()
 	ewmhCurrentDesktop()
 	Heads.EwmhWorkarea()
+	recordHistory(before)
+	pruneWorkspaces(before)
 }
 
 func WorkspaceToHead(headIndex int, wrk *workspace.Workspace) {
+	// Workspaces pinned to an output stay on its head. So a pinned workspace
+	// goes to its own head instead, and the workspace pinned to headIndex
+	// isn't replaced.
+	if head := Heads.PinnedHead(wrk); head > -1 {
+		headIndex = head
+	}
 	if headIndex == Heads.VisibleIndex(wrk) {
 		return
 	}
+	pinned := false
+	Heads.WithVisibleWorkspace(headIndex, func(w *workspace.Workspace) {
+		pinned = Heads.PinnedHead(w) == headIndex
+	})
+	if pinned {
+		logger.Message.Printf("Workspace '%s' can't be moved to head %d, "+
+			"since another workspace is pinned to it.", wrk, headIndex)
+		return
+	}
 
 	// If headIndex is the currently active head, then just activate 'wrk'
 	// greedily.
@@ -175,12 +207,64 @@ func WorkspaceToHead(headIndex int, wrk *workspace.Workspace) {
 
 	// Finally, we can just swap workspaces now without worrying about the
 	// active workspace changing.
+	before := visibleSnapshot()
 	Heads.WithVisibleWorkspace(headIndex, func(w *workspace.Workspace) {
 		Heads.SwitchWorkspaces(wrk, w)
 	})
 	ewmhVisibleDesktops()
 	ewmhCurrentDesktop()
 	Heads.EwmhWorkarea()
+	recordHistory(before)
+	pruneWorkspaces(before)
+}
+
+// AddDynamicWorkspace adds a workspace on demand and returns it, if the
+// 'dynamicworkspaces' setting is enabled. An empty name is replaced by a
+// unique one. nil is returned when dynamic workspaces are disabled or the
+// workspace can't be added.
+func AddDynamicWorkspace(name string) *workspace.Workspace {
+	if !config.Settings.DynamicWorkspaces {
+		return nil
+	}
+	if len(name) == 0 {
+		name = uniqueWorkspaceName()
+	}
+	if err := AddWorkspace(name); err != nil {
+		logger.Warning.Printf("Could not add workspace '%s': %s", name, err)
+		return nil
+	}
+	return Heads.Workspaces.Find(name)
+}
+
+// pruneWorkspaces removes the workspaces in left, which were visible before
+// a workspace switch, that are empty, hidden now and not in the 'workspaces'
+// setting, if the 'dynamicworkspaces' setting is enabled. So a dynamic
+// workspace only goes away once it is left, and not when some other switch
+// happens after it was added.
+func pruneWorkspaces(left []*workspace.Workspace) {
+	if !config.Settings.DynamicWorkspaces {
+		return
+	}
+
+	configured := make(map[string]bool, len(config.Settings.Workspaces))
+	for _, name := range config.Settings.Workspaces {
+		configured[strings.ToLower(name)] = true
+	}
+
+	for _, wrk := range left {
+		if Heads.GlobalIndex(wrk) == -1 || wrk.IsVisible() ||
+			len(wrk.Clients) > 0 || configured[strings.ToLower(wrk.Name)] {
+
+			continue
+		}
+		if len(Heads.Workspaces.Wrks) <= Heads.NumHeads() {
+			return
+		}
+		if err := RemoveWorkspace(wrk); err != nil {
+			logger.Warning.Printf("Could not remove workspace '%s': %s",
+				wrk, err)
+		}
+	}
 }
 
 func AddWorkspace(name string) error {
@@ -210,11 +294,18 @@ func RemoveWorkspace(wrk *workspace.Workspace) error {
 	if len(wrk.Clients) > 0 {
 		return fmt.Errorf("Non-empty workspace '%s' cannot be removed.", wrk)
 	}
+	if wrk.IsVisible() && Heads.Replacement(wrk) == nil {
+		return fmt.Errorf("Visible workspace '%s' cannot be removed, since "+
+			"every hidden workspace is pinned to another output.", wrk)
+	}
 	Heads.RemoveWorkspace(wrk)
 
+	// The indices of the workspaces after wrk are one less now.
 	ewmhDesktopNames()
 	ewmhNumberOfDesktops()
+	ewmhCurrentDesktop()
 	ewmhVisibleDesktops()
+	ewmhClientDesktops()
 	Heads.EwmhWorkarea()
 	event.Notify(event.RemovedWorkspace{wrk.Name})
 	return nil
@@ -224,7 +315,9 @@ func RenameWorkspace(wrk *workspace.Workspace, newName string) error {
 	if len(newName) == 0 {
 		return fmt.Errorf("workspaces must have a name of length at least one.")
 	}
-	if Heads.Workspaces.Find(newName) != nil {
+	// A workspace may be renamed to its own name in a different case.
+	found := Heads.Workspaces.Find(newName)
+	if found != nil && found != wrk {
 		return fmt.Errorf("a workspace with name '%s' already exists.", newName)
 	}
 	wrk.Rename(newName)
@@ -233,6 +326,78 @@ func RenameWorkspace(wrk *workspace.Workspace, newName string) error {
 	return nil
 }
 
+// MoveWorkspace puts wrk at the given index in the list of workspaces. Every
+// EWMH property that refers to workspaces by index is updated.
+func MoveWorkspace(wrk *workspace.Workspace, index int) error {
+	if index < 0 || index >= len(Heads.Workspaces.Wrks) {
+		return fmt.Errorf("workspace index %d is not in the range [0, %d).",
+			index, len(Heads.Workspaces.Wrks))
+	}
+	Heads.MoveWorkspace(wrk, index)
+
+	ewmhDesktopNames()
+	ewmhCurrentDesktop()
+	ewmhVisibleDesktops()
+	ewmhClientDesktops()
+	Heads.EwmhWorkarea()
+	return nil
+}
+
+// SetNumWorkspaces adds workspaces to, or removes them from, the end of the
+// list of workspaces until there are n of them. New workspaces get unique
+// names. Removing stops at the first workspace that can't be removed, e.g.,
+// because it isn't empty, in which case an error is returned.
+func SetNumWorkspaces(n int) error {
+	if n < 1 {
+		return fmt.Errorf("cannot have fewer than one workspace.")
+	}
+	for len(Heads.Workspaces.Wrks) < n {
+		if err := AddWorkspace(uniqueWorkspaceName()); err != nil {
+			return err
+		}
+	}
+	for len(Heads.Workspaces.Wrks) > n {
+		last := Heads.Workspaces.Wrks[len(Heads.Workspaces.Wrks)-1]
+		if err := RemoveWorkspace(last); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetWorkspaceNames renames the workspaces to names, in order. Empty names,
+// names of workspaces that are unchanged and names beyond the number of
+// workspaces are skipped. The first error is returned, but the remaining
+// workspaces are renamed anyway.
+//
+// Unless every name was used, _NET_DESKTOP_NAMES is set to the names of the
+// workspaces afterwards, since a pager may have written names to it that
+// were skipped.
+func SetWorkspaceNames(names []string) error {
+	var firstErr error
+	skipped := len(names) != len(Heads.Workspaces.Wrks)
+	for i, name := range names {
+		wrk := Heads.Workspaces.Get(i)
+		if wrk == nil {
+			break
+		}
+		if len(name) == 0 {
+			skipped = true
+			continue
+		}
+		if name == wrk.Name {
+			continue
+		}
+		if err := RenameWorkspace(wrk, name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil || skipped {
+		ewmhDesktopNames()
+	}
+	return firstErr
+}
+
 func RootGeomChangeFun() xevent.ConfigureNotifyFun {
 	f := func(X *xgbutil.XUtil, ev xevent.ConfigureNotifyEvent) {
 		// Before trying to reload, make sure we have enough workspaces...
@@ -0,0 +1,138 @@
+package wm
+
+import (
+	"github.com/BurntSushi/xgb/xproto"
+
+	"github.com/BurntSushi/xgbutil"
+	"github.com/BurntSushi/xgbutil/keybind"
+	"github.com/BurntSushi/xgbutil/xevent"
+
+	"github.com/onodera-punpun/sponewm/logger"
+	"github.com/onodera-punpun/sponewm/switcher"
+)
+
+// The window switcher is shown by CycleClientNext and CycleClientPrev when
+// they are bound to a key with modifiers, e.g., "Mod1-Tab". The keyboard is
+// grabbed while the switcher is shown, so that pressing a key bound to
+// either command moves the selection, releasing one of the modifiers
+// chooses the selected client and Escape cancels.
+var (
+	switcherWin *switcher.Switcher
+
+	// switcherMods are the modifiers of the key that showed the switcher,
+	// and switcherChoose is called with the item chosen.
+	switcherMods   uint16
+	switcherChoose func(item switcher.Item)
+)
+
+// switcherSetup creates the switcher window and attaches the handlers that
+// receive key events while the switcher is shown.
+func switcherSetup() {
+	switcherWin = switcher.New(X)
+	xevent.KeyPressFun(
+		func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+			switcherHandle(ev.State, ev.Detail, true)
+		}).Connect(X, switcherWin.Id())
+	xevent.KeyReleaseFun(
+		func(X *xgbutil.XUtil, ev xevent.KeyReleaseEvent) {
+			switcherHandle(ev.State, ev.Detail, false)
+		}).Connect(X, switcherWin.Id())
+}
+
+// isCycleCommand returns true if cmdName is one of the commands that drive
+// the switcher.
+func isCycleCommand(cmdName string) bool {
+	return cmdName == "CycleClientNext" || cmdName == "CycleClientPrev"
+}
+
+// switcherHandle moves the selection, chooses the selected item or cancels
+// the switcher depending on a key event.
+func switcherHandle(state uint16, detail xproto.Keycode, down bool) {
+	if !switcherWin.Showing() {
+		return
+	}
+	if !down {
+		if keybind.ModGet(X, detail)&switcherMods != 0 {
+			switcherFinish(true)
+		}
+		return
+	}
+	if keybind.KeyMatch(X, "Escape", state, detail) {
+		switcherFinish(false)
+		return
+	}
+	for _, kcmd := range keyBindings {
+		if kcmd.down && isCycleCommand(kcmd.cmdName) &&
+			kcmd.matches(state, detail) {
+
+			kcmd.run()
+			return
+		}
+	}
+}
+
+// SwitcherShowing returns true if the window switcher is shown.
+func SwitcherShowing() bool {
+	return switcherWin.Showing()
+}
+
+// SwitcherStart shows the window switcher with items, which must not be
+// empty, on the active head and grabs the keyboard. choose is called with
+// the selected item once a modifier of keyStr is released.
+//
+// False is returned, and nothing is shown, when keyStr has no modifiers to
+// wait for or the keyboard can't be grabbed. The caller should then act on
+// the item at index selected right away.
+func SwitcherStart(keyStr string, items []switcher.Item, selected int,
+	choose func(item switcher.Item)) bool {
+
+	if len(keyStr) == 0 {
+		return false
+	}
+	mods, _, err := keybind.ParseString(X, keyStr)
+	if err != nil || mods == 0 {
+		return false
+	}
+
+	switcherWin.Show(Workspace().HeadGeom(), items, selected)
+	if err := keybind.SmartGrab(X, switcherWin.Id()); err != nil {
+		logger.Warning.Printf("Could not show the window switcher: %s", err)
+		switcherWin.Hide()
+		return false
+	}
+	switcherMods = mods
+	switcherChoose = choose
+
+	// The modifiers may have been released before the keyboard was grabbed,
+	// in which case no release event is coming.
+	reply, err := xproto.QueryPointer(X.Conn(), Root.Id).Reply()
+	if err == nil && reply.Mask&mods == 0 {
+		switcherFinish(true)
+	}
+	return true
+}
+
+// SwitcherNext selects the next item in the window switcher.
+func SwitcherNext() {
+	switcherWin.Next()
+}
+
+// SwitcherPrev selects the previous item in the window switcher.
+func SwitcherPrev() {
+	switcherWin.Prev()
+}
+
+// switcherFinish hides the switcher and releases the keyboard. The selected
+// item is chosen if choose is true.
+func switcherFinish(choose bool) {
+	item := switcherWin.Selected()
+	f := switcherChoose
+
+	switcherWin.Hide()
+	keybind.SmartUngrab(X)
+	switcherMods, switcherChoose = 0, nil
+
+	if choose && item != nil && f != nil {
+		f(item)
+	}
+}
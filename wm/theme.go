@@ -9,38 +9,46 @@ import (
 )
 
 type ThemeConfig struct {
-	decorTopA, decorTopI                 *xgraphics.Image
-	decorBottomA, decorBottomI           *xgraphics.Image
-	decorLeftA, decorLeftI               *xgraphics.Image
-	decorRightA, decorRightI             *xgraphics.Image
-	decorTopLeftA, decorTopLeftI         *xgraphics.Image
-	decorTopRightA, decorTopRightI       *xgraphics.Image
-	decorBottomLeftA, decorBottomLeftI   *xgraphics.Image
-	decorBottomRightA, decorBottomRightI *xgraphics.Image
-	decorSizeTop                         int
-	decorSizeBottom                      int
-	decorSizeLeft                        int
-	decorSizeRight                       int
+	decorTopA, decorTopI, decorTopU                         *xgraphics.Image
+	decorBottomA, decorBottomI, decorBottomU                *xgraphics.Image
+	decorLeftA, decorLeftI, decorLeftU                      *xgraphics.Image
+	decorRightA, decorRightI, decorRightU                   *xgraphics.Image
+	decorTopLeftA, decorTopLeftI, decorTopLeftU             *xgraphics.Image
+	decorTopRightA, decorTopRightI, decorTopRightU          *xgraphics.Image
+	decorBottomLeftA, decorBottomLeftI, decorBottomLeftU    *xgraphics.Image
+	decorBottomRightA, decorBottomRightI, decorBottomRightU *xgraphics.Image
+	decorSizeTop                                            int
+	decorSizeBottom                                         int
+	decorSizeLeft                                           int
+	decorSizeRight                                          int
 }
 
 func (td ThemeConfig) FrameTheme() *frame.DecorTheme {
 	return &frame.DecorTheme{
 		DecorTopA:         td.decorTopA,
 		DecorTopI:         td.decorTopI,
+		DecorTopU:         td.decorTopU,
 		DecorBottomA:      td.decorBottomA,
 		DecorBottomI:      td.decorBottomI,
+		DecorBottomU:      td.decorBottomU,
 		DecorLeftA:        td.decorLeftA,
 		DecorLeftI:        td.decorLeftI,
+		DecorLeftU:        td.decorLeftU,
 		DecorRightA:       td.decorRightA,
 		DecorRightI:       td.decorRightI,
+		DecorRightU:       td.decorRightU,
 		DecorTopLeftA:     td.decorTopLeftA,
 		DecorTopLeftI:     td.decorTopLeftI,
+		DecorTopLeftU:     td.decorTopLeftU,
 		DecorTopRightA:    td.decorTopRightA,
 		DecorTopRightI:    td.decorTopRightI,
+		DecorTopRightU:    td.decorTopRightU,
 		DecorBottomLeftA:  td.decorBottomLeftA,
 		DecorBottomLeftI:  td.decorBottomLeftI,
+		DecorBottomLeftU:  td.decorBottomLeftU,
 		DecorBottomRightA: td.decorBottomRightA,
 		DecorBottomRightI: td.decorBottomRightI,
+		DecorBottomRightU: td.decorBottomRightU,
 		DecorSizeTop:      td.decorSizeTop,
 		DecorSizeBottom:   td.decorSizeBottom,
 		DecorSizeLeft:     td.decorSizeLeft,
@@ -48,33 +56,78 @@ func (td ThemeConfig) FrameTheme() *frame.DecorTheme {
 	}
 }
 
-func newTheme() *ThemeConfig {
-	return &ThemeConfig{
-		decorTopA:         newImage("active_top"),
-		decorTopI:         newImage("inactive_top"),
-		decorBottomA:      newImage("active_bottom"),
-		decorBottomI:      newImage("inactive_bottom"),
-		decorLeftA:        newImage("active_left"),
-		decorLeftI:        newImage("inactive_left"),
-		decorRightA:       newImage("active_right"),
-		decorRightI:       newImage("inactive_right"),
-		decorTopLeftA:     newImage("active_topleft"),
-		decorTopLeftI:     newImage("inactive_topleft"),
-		decorTopRightA:    newImage("active_topright"),
-		decorTopRightI:    newImage("inactive_topright"),
-		decorBottomLeftA:  newImage("active_bottomleft"),
-		decorBottomLeftI:  newImage("inactive_bottomleft"),
-		decorBottomRightA: newImage("active_bottomright"),
-		decorBottomRightI: newImage("inactive_bottomright"),
-		decorSizeTop:      newImage("active_top").Bounds().Dy(),
-		decorSizeBottom:   newImage("active_bottom").Bounds().Dx(),
-		decorSizeLeft:     newImage("active_left").Bounds().Dy(),
-		decorSizeRight:    newImage("active_right").Bounds().Dy(),
+// newTheme loads the decoration images from the "images" directory in the
+// configuration directory. The urgent images are optional, so that themes
+// without them still load. Where one is missing, the active image is used.
+func newTheme() (*ThemeConfig, error) {
+	var err error
+	load := func(side string) *xgraphics.Image {
+		if err != nil {
+			return nil
+		}
+		var pix *xgraphics.Image
+		pix, err = newImage(side)
+		return pix
 	}
+	loadUrgent := func(side string,
+		active *xgraphics.Image) *xgraphics.Image {
+
+		if pix, err := newImage(side); err == nil {
+			return pix
+		}
+		return active
+	}
+
+	td := &ThemeConfig{
+		decorTopA:         load("active_top"),
+		decorTopI:         load("inactive_top"),
+		decorBottomA:      load("active_bottom"),
+		decorBottomI:      load("inactive_bottom"),
+		decorLeftA:        load("active_left"),
+		decorLeftI:        load("inactive_left"),
+		decorRightA:       load("active_right"),
+		decorRightI:       load("inactive_right"),
+		decorTopLeftA:     load("active_topleft"),
+		decorTopLeftI:     load("inactive_topleft"),
+		decorTopRightA:    load("active_topright"),
+		decorTopRightI:    load("inactive_topright"),
+		decorBottomLeftA:  load("active_bottomleft"),
+		decorBottomLeftI:  load("inactive_bottomleft"),
+		decorBottomRightA: load("active_bottomright"),
+		decorBottomRightI: load("inactive_bottomright"),
+	}
+	if err != nil {
+		return nil, err
+	}
+	td.decorTopU = loadUrgent("urgent_top", td.decorTopA)
+	td.decorBottomU = loadUrgent("urgent_bottom", td.decorBottomA)
+	td.decorLeftU = loadUrgent("urgent_left", td.decorLeftA)
+	td.decorRightU = loadUrgent("urgent_right", td.decorRightA)
+	td.decorTopLeftU = loadUrgent("urgent_topleft", td.decorTopLeftA)
+	td.decorTopRightU = loadUrgent("urgent_topright", td.decorTopRightA)
+	td.decorBottomLeftU = loadUrgent("urgent_bottomleft",
+		td.decorBottomLeftA)
+	td.decorBottomRightU = loadUrgent("urgent_bottomright",
+		td.decorBottomRightA)
+
+	td.decorSizeTop = td.decorTopA.Bounds().Dy()
+	td.decorSizeBottom = td.decorBottomA.Bounds().Dx()
+	td.decorSizeLeft = td.decorLeftA.Bounds().Dy()
+	td.decorSizeRight = td.decorRightA.Bounds().Dy()
+
+	return td, nil
 }
 
+// loadTheme loads the theme used at startup. If the images can't be loaded,
+// an empty theme is used, which leaves windows undecorated.
 func loadTheme() *ThemeConfig {
-	return newTheme()
+	td, err := newTheme()
+	if err != nil {
+		logger.Warning.Printf("Could not load the theme: %s. Windows will "+
+			"not be decorated.", err)
+		return &ThemeConfig{}
+	}
+	return td
 }
 
 type Image struct {
@@ -85,11 +138,7 @@ func New(pix *xgraphics.Image) *Image {
 	return &Image{pix}
 }
 
-func newImage(side string) *xgraphics.Image {
-	pix, err := xgraphics.NewFileName(X, config.ConfigDir()+"/images/"+side+".png")
-	if err != nil {
-		logger.Error.Fatalln(err)
-	}
-
-	return pix
+func newImage(side string) (*xgraphics.Image, error) {
+	return xgraphics.NewFileName(X,
+		config.ConfigDir()+"/images/"+side+".png")
 }
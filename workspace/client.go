@@ -26,6 +26,7 @@ type Client interface {
 	IconifiedSet(iconified bool)
 	IsSticky() bool
 	IsActive() bool
+	IsMapped() bool
 
 	HasState(name string) bool
 	SaveState(name string)
@@ -9,7 +9,6 @@ import (
 
 	"github.com/onodera-punpun/sponewm/event"
 	"github.com/onodera-punpun/sponewm/layout"
-	"github.com/onodera-punpun/sponewm/logger"
 )
 
 type Workspace struct {
@@ -25,6 +24,13 @@ type Workspace struct {
 
 	tilers   []layout.Tiler
 	curTiler int
+
+	// master is shared by all master/stack tilers of the workspace.
+	master *layout.MasterArea
+
+	// pendingLayout is the name of the layout chosen with SetLayout while
+	// the workspace was hidden. It is used when the workspace is shown.
+	pendingLayout string
 }
 
 func (wrks *Workspaces) NewWorkspace(name string) *Workspace {
@@ -37,6 +43,7 @@ func (wrks *Workspaces) NewWorkspace(name string) *Workspace {
 
 		curFloater: 0,
 		curTiler:   0,
+		master:     layout.NewMasterArea(),
 	}
 
 	// Layouts must be listed in the order in which their corresponding
@@ -46,6 +53,13 @@ func (wrks *Workspaces) NewWorkspace(name string) *Workspace {
 	}
 	wrk.tilers = []layout.Tiler{
 		layout.NewTiling(),
+		layout.NewMasterStack(layout.MasterLeft, wrk.master),
+		layout.NewMasterStack(layout.MasterRight, wrk.master),
+		layout.NewMasterStack(layout.MasterTop, wrk.master),
+		layout.NewMasterStack(layout.MasterBottom, wrk.master),
+		layout.NewMonocle(),
+		layout.NewGrid(),
+		layout.NewBsp(),
 	}
 
 	if state, index := wrk.findLayout(wrks.defaultLayout); state != -1 {
@@ -99,16 +113,34 @@ func (wrk *Workspace) IsVisible() bool {
 	return wrk.all.heads.Geom(wrk) != nil
 }
 
+// Activate makes wrk the active workspace. A hidden workspace replaces the
+// active one on its head. A visible workspace activates its head, unless
+// greedy is true, in which case it swaps heads with the active workspace.
+//
+// Workspaces pinned to an output stay on the head of that output. So a
+// pinned workspace is always shown there and activates that head, and a
+// greedy swap doesn't happen when the active workspace is pinned.
 func (wrk *Workspace) Activate(greedy bool) {
 	if wrk.IsActive() {
 		return
 	}
 
+	heads := wrk.all.heads
+	if head := heads.PinnedHead(wrk); head > -1 {
+		if other := heads.VisibleWorkspaces()[head]; other != wrk {
+			heads.SwitchWorkspaces(wrk, other)
+		}
+		heads.ActivateWorkspace(wrk)
+		return
+	}
+
 	active := wrk.all.Active()
-	if !wrk.IsVisible() || greedy {
-		wrk.all.heads.SwitchWorkspaces(wrk, active)
+	if !wrk.IsVisible() ||
+		(greedy && heads.PinnedHead(active) == -1) {
+
+		heads.SwitchWorkspaces(wrk, active)
 	}
-	wrk.all.heads.ActivateWorkspace(wrk)
+	heads.ActivateWorkspace(wrk)
 }
 
 func (wrk *Workspace) Add(c Client) {
@@ -204,6 +236,9 @@ func (wrk *Workspace) setGeom(geom xrect.Rect) {
 
 func (wrk *Workspace) Show() {
 	wrk.setGeom(wrk.Geom())
+	if name := wrk.pendingLayout; len(name) > 0 {
+		wrk.SetLayout(name)
+	}
 	wrk.Place()
 
 	for _, c := range wrk.Clients {
@@ -300,6 +335,101 @@ func (wrk *Workspace) IconifyToggle(c Client) {
 	}
 }
 
+// Swallow hides the client old and puts new, which must have been added to
+// the workspace already, in its place in every layout. old stays on the
+// workspace as an iconified client until Unswallow brings it back.
+func (wrk *Workspace) Swallow(old, new Client) {
+	if wrk.IsVisible() {
+		if _, ok := old.Layout().(layout.Floater); ok {
+			old.SaveState("last-floating")
+		}
+	} else if old.HasState("workspace-switch") {
+		old.CopyState("workspace-switch", "last-floating")
+	}
+
+	for _, floater := range wrk.floaters {
+		replace(floater, old, new)
+	}
+	for _, tiler := range wrk.tilers {
+		if new.ShouldForceFloating() {
+			tiler.Remove(old)
+		} else {
+			replace(tiler, old, new)
+		}
+	}
+	old.IconifiedSet(true)
+
+	old.Unmap()
+	wrk.Place()
+}
+
+// Unswallow is the reverse of Swallow. It brings old back in the place of
+// new, which is about to be removed from the workspace.
+func (wrk *Workspace) Unswallow(old, new Client) {
+	for _, floater := range wrk.floaters {
+		replace(floater, new, old)
+	}
+	for _, tiler := range wrk.tilers {
+		if old.ShouldForceFloating() {
+			tiler.Remove(new)
+		} else {
+			replace(tiler, new, old)
+		}
+	}
+	old.IconifiedSet(false)
+
+	if !wrk.IsVisible() {
+		if old.HasState("last-floating") {
+			old.CopyState("last-floating", "workspace-switch")
+		}
+		return
+	}
+	if _, ok := old.Layout().(layout.Floater); ok {
+		old.LoadState("last-floating")
+	}
+	wrk.Place()
+	old.Map()
+}
+
+// Exchange moves c, which is on wrk, to other, and c2, which is on other,
+// to wrk. In the tiling layouts that can replace clients, each client takes
+// the place of the other one if both of them are tilable. Both workspaces must
+// be visible.
+func (wrk *Workspace) Exchange(c Client, other *Workspace, c2 Client) {
+	// Clients that float anyway are added like any other client, without
+	// ending up in the tiling layouts.
+	if !wrk.LayoutTiler().Exists(c) || !other.LayoutTiler().Exists(c2) {
+		other.Add(c)
+		wrk.Add(c2)
+		return
+	}
+
+	// Each client is put in the place of the other one first, so that adding
+	// it to the workspace below doesn't append it to the layouts.
+	for _, tiler := range wrk.tilers {
+		if r, ok := tiler.(layout.Replacer); ok {
+			r.Replace(c, c2)
+		}
+	}
+	for _, tiler := range other.tilers {
+		if r, ok := tiler.(layout.Replacer); ok {
+			r.Replace(c2, c)
+		}
+	}
+	other.Add(c)
+	wrk.Add(c2)
+}
+
+// replace puts new in the place of old in lay. Layouts that can't replace
+// clients, or that don't contain old, add new like any other client.
+func replace(lay layout.Layout, old, new Client) {
+	if r, ok := lay.(layout.Replacer); ok {
+		r.Replace(old, new)
+	}
+	lay.Remove(old)
+	lay.Add(new)
+}
+
 // CheckFloatingStatus queries the Floating method of a client, and if it's
 // different than what the workspace believes it should be, the proper state
 // transition will be invoked.
@@ -330,6 +460,12 @@ func (wrk *Workspace) LayoutTiler() layout.Tiler {
 	return wrk.tilers[wrk.curTiler]
 }
 
+// MasterArea returns the master count and ratio used by the master/stack
+// tilers of this workspace.
+func (wrk *Workspace) MasterArea() *layout.MasterArea {
+	return wrk.master
+}
+
 func (wrk *Workspace) addToFloaters(c Client) {
 	for _, floater := range wrk.floaters {
 		floater.Add(c)
@@ -369,21 +505,76 @@ func (wrk *Workspace) Layout(c Client) layout.Layout {
 	panic("unreachable")
 }
 
-func (wrk *Workspace) SetLayout(name string) {
+// SetLayout makes the layout named 'name' the current layout of the
+// workspace, switching between floating and tiling if necessary. An error is
+// returned if no layout named 'name' exists.
+//
+// If the workspace isn't visible, the layout is remembered and used once the
+// workspace is shown.
+func (wrk *Workspace) SetLayout(name string) error {
 	state, index := wrk.findLayout(name)
+	if state == -1 {
+		return fmt.Errorf("Unknown layout '%s'.", name)
+	}
+	if !wrk.IsVisible() {
+		wrk.pendingLayout = wrk.layoutAt(state, index).Name()
+		event.Notify(event.ChangedLayout{wrk.Name, wrk.LayoutName()})
+		return nil
+	}
+	wrk.pendingLayout = ""
+
 	switch state {
 	case Floating:
 		wrk.curFloater = index
 		wrk.LayoutStateSet(Floating)
 	case Tiling:
+		// Switching from one tiler to another doesn't change the state, so
+		// LayoutStateSet can't be used.
+		if wrk.State == Tiling && wrk.curTiler != index {
+			wrk.LayoutTiler().Unplace()
+			wrk.curTiler = index
+			wrk.LayoutTiler().Place()
+			event.Notify(event.ChangedLayout{wrk.Name, wrk.LayoutName()})
+			return nil
+		}
 		wrk.curTiler = index
 		wrk.LayoutStateSet(Tiling)
-	case -1: // couldn't find layout with name 'name'
-		logger.Warning.Printf("Unknown layout '%s'.", name)
-		return
 	default:
 		panic(fmt.Sprintf("Unknown layout state '%d'.", state))
 	}
+	return nil
+}
+
+// LayoutNames returns the names of all layouts of the workspace, floating
+// layouts first, in the order that CycleLayout goes through them.
+func (wrk *Workspace) LayoutNames() []string {
+	names := make([]string, 0, len(wrk.floaters)+len(wrk.tilers))
+	for _, lay := range wrk.floaters {
+		names = append(names, lay.Name())
+	}
+	for _, lay := range wrk.tilers {
+		names = append(names, lay.Name())
+	}
+	return names
+}
+
+// CycleLayout switches to the layout that comes after the current one in
+// LayoutNames, wrapping around at the end.
+func (wrk *Workspace) CycleLayout() {
+	cur := wrk.curFloater
+	if wrk.State == Tiling {
+		cur = len(wrk.floaters) + wrk.curTiler
+	}
+	if len(wrk.pendingLayout) > 0 {
+		state, index := wrk.findLayout(wrk.pendingLayout)
+		cur = index
+		if state == Tiling {
+			cur = len(wrk.floaters) + index
+		}
+	}
+
+	names := wrk.LayoutNames()
+	wrk.SetLayout(names[(cur+1)%len(names)])
 }
 
 func (wrk *Workspace) findLayout(name string) (state int, index int) {
@@ -419,12 +610,40 @@ func (wrk *Workspace) findLayout(name string) (state int, index int) {
 	panic(fmt.Sprintf("Unknown layout type: %T", use))
 }
 
+// LayoutName returns the name of the layout chosen for the workspace. This is
+// the layout set while the workspace was hidden, if any, even though it is
+// only used once the workspace is shown.
 func (wrk *Workspace) LayoutName() string {
+	return wrk.LayoutChosen().Name()
+}
+
+// LayoutChosen returns the layout that LayoutName names.
+func (wrk *Workspace) LayoutChosen() layout.Layout {
+	if len(wrk.pendingLayout) > 0 {
+		if state, index := wrk.findLayout(wrk.pendingLayout); state > -1 {
+			return wrk.layoutAt(state, index)
+		}
+	}
+	return wrk.LayoutCurrent()
+}
+
+// layoutAt returns the layout at index in the floating or tiling layouts,
+// depending upon state.
+func (wrk *Workspace) layoutAt(state, index int) layout.Layout {
+	if state == Floating {
+		return wrk.floaters[index]
+	}
+	return wrk.tilers[index]
+}
+
+// LayoutCurrent returns the layout that places the clients of the workspace
+// that aren't forced to float.
+func (wrk *Workspace) LayoutCurrent() layout.Layout {
 	switch wrk.State {
 	case Floating:
-		return wrk.LayoutFloater().Name()
+		return wrk.LayoutFloater()
 	case Tiling:
-		return wrk.LayoutTiler().Name()
+		return wrk.LayoutTiler()
 	}
 	panic(fmt.Sprintf("Unknown workspace layout state: %d", wrk.State))
 }
@@ -466,5 +685,5 @@ func (wrk *Workspace) LayoutStateSet(state int) {
 		panic("Layout state not implemented.")
 	}
 
-	event.Notify(event.ChangedLayout{wrk.Name})
+	event.Notify(event.ChangedLayout{wrk.Name, wrk.LayoutName()})
 }
@@ -19,6 +19,7 @@ type Heads interface {
 	IsActive(wrk *Workspace) bool
 	Geom(wrk *Workspace) xrect.Rect
 	HeadGeom(wrk *Workspace) xrect.Rect
+	PinnedHead(wrk *Workspace) int
 
 	ActivateWorkspace(wrk *Workspace)
 	SwitchWorkspaces(wrk1, wrk2 *Workspace)
@@ -68,6 +69,22 @@ func (wrks *Workspaces) Remove(wrk *Workspace) {
 	}
 }
 
+// Move puts wrk at index i, shifting the workspaces in between by one. Move
+// does nothing if wrk isn't in the set or i is out of range.
+func (wrks *Workspaces) Move(wrk *Workspace, i int) {
+	if i < 0 || i >= len(wrks.Wrks) {
+		return
+	}
+	for j, wrk2 := range wrks.Wrks {
+		if wrk == wrk2 {
+			wrks.Wrks = append(wrks.Wrks[:j], wrks.Wrks[j+1:]...)
+			wrks.Wrks = append(wrks.Wrks[:i],
+				append([]*Workspace{wrk}, wrks.Wrks[i:]...)...)
+			return
+		}
+	}
+}
+
 func (wrks *Workspaces) Active() *Workspace {
 	return wrks.heads.ActiveWorkspace()
 }
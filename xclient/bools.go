@@ -31,6 +31,10 @@ func (c *Client) Iconified() bool {
 	return c.iconified
 }
 
+func (c *Client) IsUrgent() bool {
+	return c.urgent
+}
+
 func (c *Client) hasType(atom string) bool {
 	return strIndex(atom, c.winTypes) > -1
 }
@@ -1,6 +1,8 @@
 package xclient
 
 import (
+	"time"
+
 	"github.com/BurntSushi/xgb/xproto"
 
 	"github.com/BurntSushi/xgbutil/icccm"
@@ -9,6 +11,7 @@ import (
 	"github.com/BurntSushi/xgbutil/xrect"
 	"github.com/BurntSushi/xgbutil/xwindow"
 
+	"github.com/onodera-punpun/sponewm/config"
 	"github.com/onodera-punpun/sponewm/event"
 	"github.com/onodera-punpun/sponewm/frame"
 	"github.com/onodera-punpun/sponewm/logger"
@@ -59,7 +62,11 @@ type Client struct {
 	protocols    []string
 	class        *icccm.WmClass
 	transientFor *Client
-	time         xproto.Timestamp
+
+	// time is the last _NET_WM_USER_TIME of the client. hasTime is false
+	// when the client has never set it.
+	time    xproto.Timestamp
+	hasTime bool
 
 	// unmapIgnore is the number of UnmapNotify events to ignore.
 	// When 0, an UnmapNotify event causes a client to be unmanaged.
@@ -73,7 +80,20 @@ type Client struct {
 	hadStruts bool
 	shaped    bool
 
-	attnQuit chan struct{}
+	// rule holds the actions of the window rules that matched the client
+	// when it was managed. See applyRules.
+	rule config.RuleConfig
+
+	// swallowed is the terminal that is hidden while this client takes its
+	// place. See findSwallowed.
+	swallowed *Client
+
+	// urgent is true when the client demands attention, either with the
+	// urgency hint in WM_HINTS or with _NET_WM_STATE_DEMANDS_ATTENTION,
+	// which sets demandsAttention. urgentSince is when it started to.
+	urgent           bool
+	demandsAttention bool
+	urgentSince      time.Time
 }
 
 func (c *Client) Map() {
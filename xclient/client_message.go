@@ -20,8 +20,21 @@ func (c *Client) handleClientMessage(name string, data []uint32) {
 			c.IconifyToggle()
 		}
 	case "_NET_ACTIVE_WINDOW":
-		c.Focus()
-		c.Raise()
+		// Requests from pagers (source indication 2) come from the user, so
+		// they are always granted. Applications send the time of the user
+		// action that caused the request, if they know it.
+		if data[0] == 2 {
+			c.Focus()
+			c.Raise()
+			return
+		}
+		time, hasTime := c.time, c.hasTime
+		if data[1] != 0 {
+			time, hasTime = xproto.Timestamp(data[1]), true
+		}
+		if c.focusOrDemandAttention(time, hasTime) {
+			c.Raise()
+		}
 	case "_NET_CLOSE_WINDOW":
 		c.Close()
 	case "_NET_MOVERESIZE_WINDOW":
@@ -169,6 +182,16 @@ func (c *Client) updateState(action, prop string) {
 		case "toggle":
 			c.StackBelowToggle()
 		}
+	case "_NET_WM_STATE_DEMANDS_ATTENTION":
+		switch action {
+		case "remove":
+			c.demandsAttention = false
+		case "add":
+			c.demandsAttention = true
+		case "toggle":
+			c.demandsAttention = !c.demandsAttention
+		}
+		c.refreshUrgent()
 	default:
 		logger.Warning.Printf("_NET_WM_STATE: Unsupported state '%s'.", prop)
 	}
@@ -222,6 +245,9 @@ func (c *Client) refreshState() {
 	case stack.LayerBelow:
 		atoms = append(atoms, "_NET_WM_STATE_BELOW")
 	}
+	if c.urgent {
+		atoms = append(atoms, "_NET_WM_STATE_DEMANDS_ATTENTION")
+	}
 	// ignoring _NET_WM_STATE_FOCUSED
 
 	ewmh.WmStateSet(wm.X, c.Id(), atoms)
@@ -20,13 +20,12 @@ func (c *Client) attachEventCallbacks() {
 	c.win.Listen(xproto.EventMaskPropertyChange |
 		xproto.EventMaskStructureNotify)
 
+	// Enter events are always selected, so that focus follows mouse can be
+	// switched on when the configuration is reloaded.
 	pid := c.Frame().Parent().Id
 	attrs, err := xproto.GetWindowAttributes(wm.X.Conn(), pid).Reply()
 	if err == nil {
-		masks := int(attrs.YourEventMask)
-		if config.SettingsVal["focusfollowsmouse"].(bool) {
-			masks |= xproto.EventMaskEnterWindow
-		}
+		masks := int(attrs.YourEventMask) | xproto.EventMaskEnterWindow
 		c.Frame().Parent().Listen(masks)
 	}
 
@@ -38,10 +37,7 @@ func (c *Client) attachEventCallbacks() {
 	c.cbClientMessage().Connect(wm.X, c.Id())
 	c.cbShapeNotify().Connect(wm.X, c.Id())
 
-	// Focus follows mouse?
-	if config.SettingsVal["focusfollowsmouse"].(bool) {
-		c.cbEnterNotify().Connect(wm.X, c.Frame().Parent().Id)
-	}
+	c.cbEnterNotify().Connect(wm.X, c.Frame().Parent().Id)
 	c.handleFocusIn().Connect(wm.X, c.Frame().Parent().Id)
 	c.handleFocusOut().Connect(wm.X, c.Frame().Parent().Id)
 
@@ -197,21 +193,27 @@ func ignoreFocus(modeByte, detailByte byte) bool {
 	return false
 }
 
+// cbEnterNotify focuses and raises the client when the pointer enters it,
+// depending on the focus model. This happens only once the pointer has
+// rested in the client for the 'focusdelay'.
 func (c *Client) cbEnterNotify() xevent.EnterNotifyFun {
 	f := func(X *xgbutil.XUtil, ev xevent.EnterNotifyEvent) {
-		// If the client is already active, then we don't want to do anything.
-		// This is slightly a hack to fix issue #29.
-		if c.IsActive() {
-			return
-		}
-		if c.IsMapped() {
-			if config.SettingsVal["focusfollowsmouse"].(bool) {
+		wm.Hover(func() {
+			// If the client is already active, then we don't want to do
+			// anything. This is slightly a hack to fix issue #29.
+			// The client may also be gone by the time the delay is over.
+			if c.IsActive() || !c.IsMapped() ||
+				wm.FindManagedClient(c.Id()) == nil {
+
+				return
+			}
+			if config.Settings.FocusModel != "click" {
 				c.Focus()
 			}
-			if config.SettingsVal["raisefollowsmouse"].(bool) {
+			if config.Settings.RaiseFollowsMouse {
 				c.Raise()
 			}
-		}
+		})
 	}
 	return xevent.EnterNotifyFun(f)
 }
@@ -1,11 +1,15 @@
 package xclient
 
 import (
+	"github.com/BurntSushi/xgb/xproto"
+
 	"github.com/BurntSushi/xgbutil/ewmh"
 
+	"github.com/onodera-punpun/sponewm/config"
 	"github.com/onodera-punpun/sponewm/event"
 	"github.com/onodera-punpun/sponewm/focus"
 	"github.com/onodera-punpun/sponewm/frame"
+	"github.com/onodera-punpun/sponewm/logger"
 	"github.com/onodera-punpun/sponewm/wm"
 	"github.com/onodera-punpun/sponewm/workspace"
 )
@@ -14,12 +18,64 @@ func (c *Client) Focus() {
 	focus.Focus(c)
 }
 
+// focusOrDemandAttention focuses the client if it may take the focus away
+// from the active client according to the 'focusstealing' setting. Otherwise
+// the client demands attention. time is the user time of the action that
+// asked for focus, and hasTime is false if it isn't known. True is returned
+// when the client is focused.
+func (c *Client) focusOrDemandAttention(time xproto.Timestamp,
+	hasTime bool) bool {
+
+	if !c.mayStealFocus(time, hasTime) {
+		logger.Message.Printf("Refusing to focus %s, since it would steal "+
+			"focus. It demands attention instead.", c)
+		c.demandsAttention = true
+		c.refreshUrgent()
+		return false
+	}
+	c.Focus()
+	return true
+}
+
+// mayStealFocus implements the 'focusstealing' setting.
+func (c *Client) mayStealFocus(time xproto.Timestamp, hasTime bool) bool {
+	policy := config.Settings.FocusStealing
+	if policy == "off" {
+		return true
+	}
+
+	active, ok := focus.Current().(*Client)
+	if !ok || active == c || active.transient(c) {
+		return true
+	}
+
+	switch {
+	case !hasTime:
+		return policy == "smart"
+	case time == 0:
+		// The client asks not to be focused.
+		return false
+	case !active.hasTime:
+		return true
+	case policy == "strict":
+		return timeAfter(time, active.time)
+	}
+	return !timeAfter(active.time, time)
+}
+
+// timeAfter returns true if the X timestamp t1 is later than t2. Timestamps
+// wrap around, so they are compared like the X server does.
+func timeAfter(t1, t2 xproto.Timestamp) bool {
+	return int32(t1-t2) > 0
+}
+
 func (c *Client) Focused() {
 	c.frame.Active()
 	c.state = frame.Active
 	focus.SetFocus(c)
 	ewmh.ActiveWindowSet(wm.X, c.Id())
 	c.addState("_NET_WM_STATE_FOCUSED")
+	c.clearUrgent()
 
 	event.Notify(event.FocusedClient{c.Id()})
 	event.Notify(event.ChangedActiveClient{c.Id()})
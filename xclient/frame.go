@@ -5,6 +5,7 @@ import (
 
 	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/icccm"
+	"github.com/BurntSushi/xgbutil/mousebind"
 	"github.com/BurntSushi/xgbutil/xrect"
 
 	"github.com/onodera-punpun/sponewm/frame"
@@ -59,6 +60,19 @@ func (c *Client) FramePieceMouseSetup(piece string, pieceid xproto.Window) {
 	wm.FramePieceMouseSetup(c, piece, pieceid)
 }
 
+// ReloadConfig applies the current mouse bindings and theme to the client.
+// The decorations are rebuilt in place, so the client keeps its frame.
+func (c *Client) ReloadConfig() {
+	pid := c.Frame().Parent().Id
+	mousebind.Detach(wm.X, c.Id())
+	mousebind.Detach(wm.X, pid)
+	wm.ClientMouseSetup(c)
+	wm.FrameMouseSetup(c, pid)
+
+	c.frames.decor.SetTheme(wm.Theme.FrameTheme())
+	c.refreshExtents()
+}
+
 // GravitizeX adjusts the x coordinate of a window's position using the gravity
 // value set. Gravity refers to the way (x, y) coordinates are interpreted with
 // respect to a client's decorations. See Section 4.1.2.3 of the ICCCM for more
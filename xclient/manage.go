@@ -61,7 +61,6 @@ func New(id xproto.Window) *Client {
 		fullscreen:  false,
 		skipTaskbar: false,
 		skipPager:   false,
-		attnQuit:    make(chan struct{}, 0),
 	}
 
 	c.manage()
@@ -69,13 +68,18 @@ func New(id xproto.Window) *Client {
 	if !wm.Startup {
 		event.Notify(event.ManagedClient{c.Id()})
 	}
-	if !c.iconified {
+	// Clients that went to a hidden workspace, e.g., because of a window rule
+	// or ExecOn, are mapped once their workspace is shown.
+	if !c.iconified && c.workspace.IsVisible() {
 		c.Map()
 		if !wm.Startup && c.PrimaryType() == TypeNormal {
-			if !config.SettingsVal["focusfollowsmouse"].(bool) {
-				c.Focus()
+			if config.Settings.FocusModel == "click" {
+				c.focusOrDemandAttention(c.time, c.hasTime)
 			}
 		}
+	} else if !c.iconified {
+		icccm.WmStateSet(wm.X, c.Id(),
+			&icccm.WmState{State: icccm.StateIconic})
 	}
 
 	return c
@@ -96,6 +100,11 @@ func (c *Client) manage() {
 	c.frames = c.newClientFrames()
 	c.states = c.newClientStates()
 
+	// Window rules must be applied before the workspace of the client is
+	// decided, so that it never shows up on the wrong one.
+	c.applyRules()
+	c.swallowed = c.findSwallowed()
+
 	presumedWorkspace := c.findPresumedWorkspace()
 
 	c.moveToProperHead(presumedWorkspace)
@@ -111,8 +120,13 @@ func (c *Client) manage() {
 	} else {
 		presumedWorkspace.Add(c)
 	}
+	if c.swallowed != nil {
+		logger.Message.Printf("Client %s swallows %s.", c, c.swallowed)
+		c.swallowed.workspace.(*workspace.Workspace).Swallow(c.swallowed, c)
+	}
 
 	c.updateInitStates()
+	c.refreshUrgent()
 	ewmh.WmAllowedActionsSet(wm.X, c.Id(), allowedActions)
 
 	err := xproto.ChangeSaveSetChecked(
@@ -140,13 +154,15 @@ func (c *Client) maybeInitPlace(presumedWorkspace workspace.Workspacer) {
 				frame:     c.frame,
 				maximized: c.maximized,
 			}
-		} else if wm.Startup {
-			// This is a bit tricky. If the window manager is starting up and
-			// has to manage existing clients, then we need to find which
-			// head the client is on and save its state. This is so future
-			// workspace switches will be able to place the client
-			// appropriately.
-			// (This is most common on a SponeWM restart.)
+		} else {
+			// This is a bit tricky. If the client goes to a hidden
+			// workspace, then we need to find which head the client is on
+			// and save its state. This is so future workspace switches will
+			// be able to place the client appropriately. Workspace.Add
+			// copies this state to "workspace-switch", which is loaded when
+			// the workspace is shown.
+			// (This is most common on a SponeWM restart, or with a window
+			// rule or ExecOn that names a hidden workspace.)
 			// We refer to detected workspace as "fake" because the client
 			// isn't on a visible workspace (see above), and therefore the
 			// visible workspace returned by FindMostOverlap *cannot* contain
@@ -167,6 +183,12 @@ func (c *Client) maybeInitPlace(presumedWorkspace workspace.Workspacer) {
 		}
 	}()
 
+	// The geometry of a window rule is always used.
+	if c.rule.Geometry != nil {
+		c.placeByRule(presumedWorkspace)
+		return
+	}
+
 	// Any client that isn't normal doesn't get placed.
 	// Let it do what it do, baby.
 	if c.PrimaryType() != TypeNormal {
@@ -241,6 +263,13 @@ func (c *Client) fetchXProperties() {
 		ewmh.WmStateSet(wm.X, c.Id(), c.winStates)
 	}
 
+	c.refreshName()
+
+	if t, err := ewmh.WmUserTimeGet(wm.X, c.Id()); err == nil {
+		c.time = xproto.Timestamp(t)
+		c.hasTime = true
+	}
+
 	c.class, err = icccm.WmClassGet(wm.X, c.Id())
 	if err != nil {
 		logger.Warning.Printf("Could not find window class for window %X: %s",
@@ -348,10 +377,31 @@ func (c *Client) updateInitStates() {
 }
 
 // findPresumedWorkspace inspects a client before it is fully managed to
-// see which workspace it should go to. Basically, if _NET_WM_DESKTOP is
-// to a valid workspace number, then we grant the request. Otherwise, we use
-// the current workspace.
+// see which workspace it should go to. Windows of programs started with
+// ExecOn come first, followed by the window rules that matched the client.
+// Otherwise, if _NET_WM_DESKTOP is set to a valid
+// workspace number, then we grant the request. Otherwise, we use the current
+// workspace.
 func (c *Client) findPresumedWorkspace() workspace.Workspacer {
+	if c.swallowed != nil {
+		return c.swallowed.workspace
+	}
+	if name := c.spawnedWorkspace(); len(name) > 0 {
+		if wrk := wm.Heads.Workspaces.Find(name); wrk != nil {
+			return wrk
+		}
+	}
+	if c.rule.Sticky {
+		return wm.StickyWrk
+	}
+	if len(c.rule.Workspace) > 0 {
+		if wrk := wm.Heads.Workspaces.Find(c.rule.Workspace); wrk != nil {
+			return wrk
+		}
+		logger.Warning.Printf("A rule for client '%s' refers to the unknown "+
+			"workspace '%s'.", c, c.rule.Workspace)
+	}
+
 	d, err := ewmh.WmDesktopGet(wm.X, c.Id())
 	if err != nil {
 		return wm.Workspace()
@@ -365,6 +415,15 @@ func (c *Client) findPresumedWorkspace() workspace.Workspacer {
 	return wm.Heads.Workspaces.Get(int(d))
 }
 
+// spawnedWorkspace returns the name of the workspace requested by ExecOn for
+// the program that the client belongs to, if any.
+func (c *Client) spawnedWorkspace() string {
+	pid, _ := ewmh.WmPidGet(wm.X, c.Id())
+	startupID, _ := xprop.PropValStr(
+		xprop.GetProperty(wm.X, c.Id(), "_NET_STARTUP_ID"))
+	return wm.SpawnedWorkspace(int(pid), startupID)
+}
+
 // moveToProperHead is used to make sure a newly managed client is placed on
 // the correct monitor.
 //
@@ -6,19 +6,28 @@ import (
 	"github.com/BurntSushi/xgbutil/ewmh"
 	"github.com/BurntSushi/xgbutil/icccm"
 
+	"github.com/onodera-punpun/sponewm/event"
 	"github.com/onodera-punpun/sponewm/layout"
 	"github.com/onodera-punpun/sponewm/wm"
 )
 
 func (c *Client) handleProperty(name string) {
 	switch name {
-	case "_NET_WM_VISIBLE_NAME":
-		fallthrough
-	case "_NET_WM_NAME":
-		fallthrough
+	case "_NET_WM_VISIBLE_NAME", "_NET_WM_NAME", "WM_NAME":
+		if c.refreshName() {
+			event.Notify(event.ChangedClientName{c.Id(), c.name})
+			c.reapplyRules()
+		}
+	case "WM_CLASS":
+		class, err := icccm.WmClassGet(wm.X, c.Id())
+		if err == nil && *class != *c.class {
+			c.class = class
+			c.reapplyRules()
+		}
 	case "WM_HINTS":
 		if hints, err := icccm.WmHintsGet(wm.X, c.Id()); err == nil {
 			c.hints = hints
+			c.refreshUrgent()
 		}
 	case "WM_NORMAL_HINTS":
 		if nhints, err := icccm.WmNormalHintsGet(wm.X, c.Id()); err == nil {
@@ -33,6 +42,7 @@ func (c *Client) handleProperty(name string) {
 	case "_NET_WM_USER_TIME":
 		if newTime, err := ewmh.WmUserTimeGet(wm.X, c.Id()); err == nil {
 			c.time = xproto.Timestamp(newTime)
+			c.hasTime = true
 		}
 	case "_NET_WM_STRUT_PARTIAL":
 		c.maybeApplyStruts()
@@ -63,6 +73,27 @@ func (c *Client) handleProperty(name string) {
 	}
 }
 
+// refreshName fetches the title of the client, preferring the EWMH names
+// over WM_NAME. It returns true if the title changed.
+func (c *Client) refreshName() bool {
+	name, _ := ewmh.WmVisibleNameGet(wm.X, c.Id())
+	if len(name) == 0 {
+		name, _ = ewmh.WmNameGet(wm.X, c.Id())
+	}
+	if len(name) == 0 {
+		name, _ = icccm.WmNameGet(wm.X, c.Id())
+	}
+	if len(name) == 0 {
+		name = "N/A"
+	}
+
+	if name == c.name {
+		return false
+	}
+	c.name = name
+	return true
+}
+
 func (c *Client) maybeApplyStruts() {
 	if strut, _ := ewmh.WmStrutPartialGet(wm.X, c.Id()); strut != nil {
 		c.hadStruts = true
@@ -0,0 +1,186 @@
+package xclient
+
+import (
+	"github.com/BurntSushi/xgbutil/xrect"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/heads"
+	"github.com/onodera-punpun/sponewm/layout"
+	"github.com/onodera-punpun/sponewm/logger"
+	"github.com/onodera-punpun/sponewm/wm"
+	"github.com/onodera-punpun/sponewm/workspace"
+)
+
+// matchRules returns the window rules in settings.json that match the
+// client, in order. When titleChange is true, only the rules flagged with
+// 'ontitlechange' are matched.
+func (c *Client) matchRules(titleChange bool) []config.RuleConfig {
+	matched := make([]config.RuleConfig, 0)
+	for i, rule := range config.Settings.Rules {
+		if titleChange && !rule.OnTitleChange {
+			continue
+		}
+		ok, err := wm.RuleMatch(rule.Match, c)
+		if err != nil {
+			logger.Warning.Printf("Could not match rule %d against client "+
+				"'%s': %s", i+1, c, err)
+			continue
+		}
+		if ok {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// applyRules merges the actions of the window rules that match a new client
+// into c.rule. When rules disagree, the last one wins.
+//
+// Actions that only change the client itself are applied right away, while
+// the workspace and geometry are used later by findPresumedWorkspace and
+// maybeInitPlace.
+func (c *Client) applyRules() {
+	for _, rule := range c.matchRules(false) {
+		if len(rule.Workspace) > 0 {
+			c.rule.Workspace = rule.Workspace
+		}
+		c.rule.Floating = c.rule.Floating || rule.Floating
+		c.rule.Sticky = c.rule.Sticky || rule.Sticky
+		c.rule.SkipTaskbar = c.rule.SkipTaskbar || rule.SkipTaskbar
+		if len(rule.Layer) > 0 {
+			c.rule.Layer = rule.Layer
+		}
+		if len(rule.Frame) > 0 {
+			c.rule.Frame = rule.Frame
+		}
+		if rule.Geometry != nil {
+			c.rule.Geometry = rule.Geometry
+		}
+	}
+
+	if c.rule.Floating {
+		c.floating = true
+	}
+	switch c.rule.Frame {
+	case "decor":
+		c.FrameDecor()
+	case "nada":
+		c.FrameNada()
+	}
+
+	// The remaining actions are states, which are applied along with the
+	// states the client asked for in updateInitStates.
+	if c.rule.Sticky {
+		c.addState("_NET_WM_STATE_STICKY")
+	}
+	if c.rule.SkipTaskbar {
+		c.addState("_NET_WM_STATE_SKIP_TASKBAR")
+	}
+	switch c.rule.Layer {
+	case "above":
+		c.removeState("_NET_WM_STATE_BELOW")
+		c.addState("_NET_WM_STATE_ABOVE")
+	case "below":
+		c.removeState("_NET_WM_STATE_ABOVE")
+		c.addState("_NET_WM_STATE_BELOW")
+	}
+}
+
+// reapplyRules is called when the title or class of a managed client
+// changes. The window rules flagged with 'ontitlechange' are matched again,
+// and the actions of those that match are applied in order.
+func (c *Client) reapplyRules() {
+	// Titles change all the time, so nothing is matched unless a rule asks
+	// for it.
+	if !titleChangeRules() {
+		return
+	}
+	for _, rule := range c.matchRules(true) {
+		if rule.Sticky {
+			c.stick()
+		} else if len(rule.Workspace) > 0 && !c.sticky {
+			if wrk := wm.Heads.Workspaces.Find(rule.Workspace); wrk != nil {
+				wrk.Add(c)
+			} else {
+				logger.Warning.Printf("A rule for client '%s' refers to the "+
+					"unknown workspace '%s'.", c, rule.Workspace)
+			}
+		}
+		if rule.Floating {
+			c.Float()
+		}
+		if rule.SkipTaskbar {
+			c.SkipTaskbarSet(true)
+		}
+		switch rule.Layer {
+		case "above":
+			c.stackAbove()
+		case "below":
+			c.stackBelow()
+		}
+
+		// Frames and geometry are up to the layout of tiled clients.
+		if _, ok := c.Layout().(layout.Floater); !ok {
+			continue
+		}
+		switch rule.Frame {
+		case "decor":
+			c.FrameDecor()
+		case "nada":
+			c.FrameNada()
+		}
+		if rule.Geometry != nil && c.Workspace().IsVisible() {
+			x, y, w, h := c.ruleGeom(c.Workspace().Geom(), rule.Geometry)
+			c.LayoutMoveResize(x, y, w, h)
+		}
+	}
+}
+
+// titleChangeRules returns true if any window rule is flagged with
+// 'ontitlechange'.
+func titleChangeRules() bool {
+	for _, rule := range config.Settings.Rules {
+		if rule.OnTitleChange {
+			return true
+		}
+	}
+	return false
+}
+
+// placeByRule moves and resizes the client to the geometry of its window
+// rules, relative to the geometry of the workspace it is presumed to be on.
+// If that workspace is hidden, the geometry of the active workspace is used.
+// The client is then converted to the head of its workspace when it is
+// shown, see maybeInitPlace.
+func (c *Client) placeByRule(presumedWorkspace workspace.Workspacer) {
+	geom := wm.Workspace().Geom()
+	if presumedWorkspace.IsVisible() {
+		geom = presumedWorkspace.Geom()
+	}
+	x, y, w, h := c.ruleGeom(geom, c.rule.Geometry)
+	c.MoveResizeValid(x, y, w, h)
+}
+
+// ruleGeom converts the geometry g of a window rule to a frame geometry in
+// the workspace geometry geom. Positions in pixels are relative to geom.
+// Invalid values are rejected when the settings are read, so the results of
+// heads.ParsePos and heads.ParseDim are always valid.
+func (c *Client) ruleGeom(geom xrect.Rect, g []interface{}) (x, y, w, h int) {
+	x, _ = heads.ParsePos(geom, g[0], false)
+	y, _ = heads.ParsePos(geom, g[1], true)
+	if _, ok := g[0].(int); ok {
+		x += geom.X()
+	}
+	if _, ok := g[1].(int); ok {
+		y += geom.Y()
+	}
+
+	w, h = c.frame.Geom().Width(), c.frame.Geom().Height()
+	if d, _ := heads.ParseDim(geom, g[2], false); d > 0 {
+		w = d
+	}
+	if d, _ := heads.ParseDim(geom, g[3], true); d > 0 {
+		h = d
+	}
+	return
+}
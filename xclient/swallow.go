@@ -0,0 +1,103 @@
+package xclient
+
+import (
+	"strings"
+
+	"github.com/BurntSushi/xgbutil/ewmh"
+
+	"github.com/onodera-punpun/sponewm/config"
+	"github.com/onodera-punpun/sponewm/logger"
+	"github.com/onodera-punpun/sponewm/wm"
+	"github.com/onodera-punpun/sponewm/workspace"
+)
+
+// findSwallowed returns the terminal that a new client swallows, or nil if
+// it doesn't swallow any. A terminal is swallowed when its class is in the
+// 'swallow' setting and the process of the client, found with _NET_WM_PID,
+// was started from the process of the terminal.
+func (c *Client) findSwallowed() *Client {
+	if len(config.Settings.Swallow) == 0 {
+		return nil
+	}
+	if c.PrimaryType() != TypeNormal || c.transientFor != nil || c.iconified {
+		return nil
+	}
+
+	// Rules that send the client somewhere else win, and terminals started
+	// from other terminals are left alone.
+	if c.rule.Sticky || len(c.rule.Workspace) > 0 {
+		return nil
+	}
+	if classIn(c.Class().Class, config.Settings.NoSwallow) ||
+		classIn(c.Class().Class, config.Settings.Swallow) {
+		return nil
+	}
+
+	pid, err := ewmh.WmPidGet(wm.X, c.Id())
+	if err != nil || pid == 0 {
+		return nil
+	}
+	for _, c2_ := range wm.Clients {
+		term := c2_.(*Client)
+		if term.iconified || !classIn(term.Class().Class,
+			config.Settings.Swallow) {
+			continue
+		}
+		if _, ok := term.workspace.(*workspace.Workspace); !ok {
+			continue
+		}
+
+		termPid, err := ewmh.WmPidGet(wm.X, term.Id())
+		if err != nil || termPid == 0 || termPid == pid {
+			continue
+		}
+		if wm.IsDescendant(int(pid), int(termPid)) {
+			return term
+		}
+	}
+	return nil
+}
+
+// unswallow brings back the terminal swallowed by the client, in the place
+// of the client on its current workspace. It is called when the client is
+// unmanaged.
+func (c *Client) unswallow() {
+	term := c.swallowed
+	c.swallowed = nil
+	if term == nil {
+		return
+	}
+
+	wrk, ok := c.workspace.(*workspace.Workspace)
+	if !ok {
+		// There is no place to take over on the sticky workspace, so the
+		// terminal is simply deiconified where it is.
+		term.workspace.IconifyToggle(term)
+		return
+	}
+	if term.workspace != wrk {
+		wrk.Add(term)
+	}
+	wrk.Unswallow(term, c)
+	logger.Message.Printf("Client %s gives the place of %s back.", c, term)
+}
+
+// forgetSwallowed makes sure that no client refers to c as the terminal it
+// swallowed. It is called when c is unmanaged.
+func (c *Client) forgetSwallowed() {
+	for _, c2_ := range wm.Clients {
+		if c2 := c2_.(*Client); c2.swallowed == c {
+			c2.swallowed = nil
+		}
+	}
+}
+
+// classIn returns true if class is in classes, ignoring case.
+func classIn(class string, classes []string) bool {
+	for _, class2 := range classes {
+		if strings.EqualFold(class, class2) {
+			return true
+		}
+	}
+	return false
+}
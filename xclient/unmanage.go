@@ -31,6 +31,8 @@ func (c *Client) unmanage() {
 	c.frame.Unmap()
 	c.win.Detach()
 	icccm.WmStateSet(wm.X, c.Id(), &icccm.WmState{State: icccm.StateWithdrawn})
+	c.unswallow()
+	c.forgetSwallowed()
 	focus.Remove(c)
 	wm.FocusFallback()
 	stack.Remove(c)
@@ -0,0 +1,65 @@
+package xclient
+
+import (
+	"time"
+
+	"github.com/BurntSushi/xgbutil/icccm"
+
+	"github.com/onodera-punpun/sponewm/event"
+	"github.com/onodera-punpun/sponewm/wm"
+)
+
+// refreshUrgent checks whether the client demands attention after its
+// WM_HINTS or _NET_WM_STATE_DEMANDS_ATTENTION changed. When that changes,
+// _NET_WM_STATE is updated, the frame is drawn again and an UrgentClient
+// event is sent. The active client never stays urgent.
+func (c *Client) refreshUrgent() {
+	urgent := c.hints.Flags&icccm.HintUrgency > 0 || c.demandsAttention
+	if urgent && c.IsActive() {
+		c.clearUrgent()
+		return
+	}
+	if urgent == c.urgent {
+		return
+	}
+
+	c.urgent = urgent
+	if urgent {
+		c.urgentSince = time.Now()
+		c.addState("_NET_WM_STATE_DEMANDS_ATTENTION")
+	} else {
+		c.removeState("_NET_WM_STATE_DEMANDS_ATTENTION")
+	}
+	if !c.IsActive() {
+		c.frame.Inactive()
+	}
+
+	event.Notify(event.UrgentClient{c.Id(), urgent})
+}
+
+// clearUrgent stops the client from demanding attention. The urgency hint is
+// removed from WM_HINTS too, since the client may not do so itself.
+func (c *Client) clearUrgent() {
+	c.demandsAttention = false
+	if c.hints.Flags&icccm.HintUrgency > 0 {
+		c.hints.Flags &^= icccm.HintUrgency
+		icccm.WmHintsSet(wm.X, c.Id(), c.hints)
+	}
+	c.refreshUrgent()
+}
+
+// OldestUrgent returns the client that has been demanding attention for the
+// longest time, or nil if no client is.
+func OldestUrgent() *Client {
+	var oldest *Client
+	for _, c_ := range wm.Clients {
+		c, ok := c_.(*Client)
+		if !ok || !c.urgent {
+			continue
+		}
+		if oldest == nil || c.urgentSince.Before(oldest.urgentSince) {
+			oldest = c
+		}
+	}
+	return oldest
+}